@@ -1,25 +1,156 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/adityapore231/Watch-my-pod/internal/monitor"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 func main() {
-	// 1. Create the Kubernetes clientset
-	clientset, err := monitor.NewClientset()
+	testNotifiers := flag.Bool("test-notifiers", false, "send a test alert through each configured notifier and exit")
+	once := flag.Bool("once", false, "list the bad pods once, without alerting, and exit non-zero if there are any")
+	podsFile := flag.String("pods-file", "", "run detection over the pods in this JSON or YAML file instead of a cluster, print the alerts and exit")
+	selfTest := flag.Bool("selftest", false, "create a failing pod, check it is detected and alerted on, delete it and exit")
+	flag.Parse()
+
+	// 1. Load the configuration
+	cfg, err := monitor.LoadConfig()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	monitor.RegisterMetrics(cfg.Environment, cfg.StaticLabels)
+	if effective, err := json.Marshal(cfg.EffectiveConfig()); err == nil {
+		log.Printf("Effective configuration: %s", effective)
+	}
+
+	if *podsFile != "" {
+		os.Exit(checkPodsFile(cfg, *podsFile))
+	}
+
+	// 2. Build the notifiers
+	notifiers, err := monitor.NewNotifiers(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create notifiers: %v", err)
+	}
+
+	if *testNotifiers {
+		ok := monitor.TestNotifiers(context.Background(), notifiers, os.Stdout)
+		monitor.CloseNotifiers(notifiers)
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
+	defer monitor.CloseNotifiers(notifiers)
+
+	// 3. Create the Kubernetes clientset
+	clientset, restConfig, err := newClientset(cfg)
 	if err != nil {
 		log.Fatalf("Failed to create clientset: %v", err)
 	}
 
-	// 2. Create the controller
-	controller := monitor.NewController(clientset)
+	if *once {
+		code := checkOnce(clientset, cfg)
+		monitor.CloseNotifiers(notifiers)
+		os.Exit(code)
+	}
+
+	if *selfTest {
+		ok := monitor.SelfTest(context.Background(), clientset, cfg, notifiers, os.Stdout)
+		monitor.CloseNotifiers(notifiers)
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 4. Create the controller, sharing the alert cache through Redis if configured
+	var opts []monitor.Option
+	tp, err := monitor.NewTracerProvider(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	if tp != nil {
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := tp.Shutdown(ctx); err != nil {
+				log.Printf("ERROR: Failed to flush traces: %v", err)
+			}
+		}()
+		monitor.InstallTracerProvider(tp)
+		opts = append(opts, monitor.WithTracerProvider(tp))
+	}
+	if cfg.RedisURL != "" {
+		cache, err := monitor.NewRedisAlertCache(context.Background(), cfg.RedisURL, cfg.RedisKeyPrefix)
+		if err != nil {
+			log.Fatalf("Failed to create alert cache: %v", err)
+		}
+		defer cache.Close()
+		opts = append(opts, monitor.WithAlertCache(cache))
+	}
+	if cfg.WatchAlertRules {
+		dyn, err := dynamic.NewForConfig(restConfig)
+		if err != nil {
+			log.Fatalf("Failed to create dynamic client: %v", err)
+		}
+		opts = append(opts, monitor.WithDynamicClient(dyn))
+	}
+	if cfg.AgentSampleRate > 0 {
+		sampler, err := monitor.NewResponseSampler(cfg)
+		if err != nil {
+			log.Fatalf("Failed to create agent response sampler: %v", err)
+		}
+		defer sampler.Close()
+		opts = append(opts, monitor.WithResponseSampler(sampler))
+	}
+	if cfg.AuditSink != "" {
+		audit, err := monitor.NewAuditLog(cfg)
+		if err != nil {
+			log.Fatalf("Failed to open audit sink: %v", err)
+		}
+		defer audit.Close()
+		opts = append(opts, monitor.WithAuditLog(audit))
+	}
+	controller := monitor.NewController(clientset, cfg, notifiers, opts...)
+
+	// 5. Serve metrics, recent alerts and the probes, on their own port if HEALTH_ADDR is set
+	health := http.NewServeMux()
+	health.Handle("/healthz", monitor.HealthHandler())
+	health.Handle("/readyz", controller.ReadyHandler())
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", monitor.MetricsHandler())
+	mux.Handle("/alerts", controller.AlertsHandler())
+	if cfg.AdminToken != "" {
+		mux.Handle("/alerts/clear", controller.ClearAlertsHandler())
+	}
+	if cfg.ExposeConfig {
+		mux.Handle("/config", monitor.ConfigHandler(cfg))
+	}
+	if cfg.HealthAddr == "" || cfg.HealthAddr == cfg.MetricsAddr {
+		mux.Handle("/healthz", health)
+		mux.Handle("/readyz", health)
+	} else {
+		// A dedicated probe port exists for the kubelet, so failing to serve it is fatal
+		serveHTTP("health", cfg.HealthAddr, true, health)
+	}
+	serveHTTP("metrics", cfg.MetricsAddr, cfg.MetricsBindFatal, mux)
 
-	// 3. Set up a channel to handle OS shutdown signals
+	// 6. Set up a channel to handle OS shutdown signals
 	stopCh := make(chan struct{})
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -28,8 +159,102 @@ func main() {
 		<-sigCh
 		log.Println("Shutdown signal received, stopping controller...")
 		close(stopCh)
+
+		// Don't let a wedged shutdown ignore the operator
+		select {
+		case <-sigCh:
+			log.Println("Second shutdown signal received, exiting immediately")
+		case <-time.After(cfg.ShutdownTimeout):
+			log.Printf("Shutdown did not complete within %v, exiting", cfg.ShutdownTimeout)
+		}
+		os.Exit(1)
 	}()
 
-	// 4. Run the controller
+	// 7. Run the controller
 	controller.Run(stopCh)
 }
+
+// checkOnce reports the bad pods for --once and returns the exit code:
+// 0 if there are none, 1 if there are, 2 if the pods could not be listed
+func checkOnce(clientset kubernetes.Interface, cfg *monitor.Config) int {
+	controller := monitor.NewController(clientset, cfg, nil)
+	bad, err := controller.CheckOnce(context.Background(), os.Stdout)
+	if err != nil {
+		log.Printf("ERROR: %v", err)
+		return 2
+	}
+	if bad > 0 {
+		return 1
+	}
+	return 0
+}
+
+// checkPodsFile reports the bad pods and alerts for --pods-file and returns the
+// exit code, like checkOnce: 0 if no pod is bad, 1 if some are, 2 on error
+func checkPodsFile(cfg *monitor.Config, path string) int {
+	bad, err := monitor.CheckPodsFile(context.Background(), cfg, path, os.Stdout)
+	if err != nil {
+		log.Printf("ERROR: %v", err)
+		return 2
+	}
+	if bad > 0 {
+		return 1
+	}
+	return 0
+}
+
+// newClientset creates the clientset and checks the API server answers,
+// retrying with backoff so a cold cluster start doesn't crash-loop the monitor
+func newClientset(cfg *monitor.Config) (kubernetes.Interface, *rest.Config, error) {
+	backoff := cfg.ClientInitBackoff
+	for attempt := 1; ; attempt++ {
+		config, clientset, err := connect(cfg)
+		if err == nil {
+			return clientset, config, nil
+		}
+		if attempt > cfg.ClientInitRetries {
+			return nil, nil, err
+		}
+		log.Printf("WARNING: Client creation attempt %d/%d failed, retrying in %v: %v",
+			attempt, cfg.ClientInitRetries+1, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// connect creates the clientset and checks that the API server answers
+func connect(cfg *monitor.Config) (*rest.Config, kubernetes.Interface, error) {
+	config, err := monitor.RESTConfig(cfg.KubeconfigSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := clientset.Discovery().ServerVersion(); err != nil {
+		return nil, nil, fmt.Errorf("API server is not reachable: %w", err)
+	}
+	return config, clientset, nil
+}
+
+// serveHTTP starts an HTTP server in the background.
+// Pod watching is the primary job, so unless fatal is set a failure to
+// bind only disables the server instead of stopping the monitor.
+func serveHTTP(name, addr string, fatal bool, handler http.Handler) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		if fatal {
+			log.Fatalf("Failed to listen on %s: %v", addr, err)
+		}
+		log.Printf("ERROR: Failed to listen on %s, continuing without the %s server: %v", addr, name, err)
+		return
+	}
+
+	log.Printf("Serving %s on %s", name, addr)
+	go func() {
+		if err := http.Serve(ln, handler); err != nil {
+			log.Printf("ERROR: %s server stopped: %v", name, err)
+		}
+	}()
+}
@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// clearResult is the response of /alerts/clear
+type clearResult struct {
+	Cleared []string `json:"cleared"`
+}
+
+// ClearAlertsHandler removes suppression so the next bad-state observation alerts immediately.
+// POST ?pod=namespace/name clears one pod, POST ?all=true clears the whole cache.
+// Requests must carry ADMIN_TOKEN as a bearer token.
+func (c *Controller) ClearAlertsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !c.adminAuthorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var keys []string
+		pod := r.URL.Query().Get("pod")
+		switch {
+		case r.URL.Query().Get("all") == "true":
+			err := c.alertCache.Range(r.Context(), func(key string, _ AlertCacheEntry) bool {
+				keys = append(keys, key)
+				return true
+			})
+			if err != nil {
+				log.Printf("ERROR: Failed to list alert cache entries: %v", err)
+				http.Error(w, "failed to list alert cache", http.StatusInternalServerError)
+				return
+			}
+		case strings.Count(pod, "/") == 1:
+			// Under DEDUP_SCOPE=owner the pod's alerts are deduplicated on its owner's key
+			keys = c.podCacheKeys(pod)
+		default:
+			http.Error(w, "expected ?pod=namespace/name or ?all=true", http.StatusBadRequest)
+			return
+		}
+
+		for _, key := range keys {
+			c.cacheClear(key)
+		}
+		log.Printf("Cleared %d alert cache entries on request from %s", len(keys), r.RemoteAddr)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(clearResult{Cleared: keys}); err != nil {
+			log.Printf("ERROR: Failed to encode /alerts/clear response: %v", err)
+		}
+	})
+}
+
+// adminAuthorized reports whether the request carries the admin token
+func (c *Controller) adminAuthorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || c.cfg.AdminToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(c.cfg.AdminToken)) == 1
+}
@@ -0,0 +1,226 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// agentSummarizePath is the default agent endpoint that analyzes a failing pod
+const agentSummarizePath = "/summarize-pod"
+
+// maxAgentResponseBytes bounds how much of a successful agent response is captured
+const maxAgentResponseBytes = 1 << 20
+
+// maxAgentErrorBytes bounds how much of an agent error response is read and logged
+const maxAgentErrorBytes = 4 << 10
+
+// AgentResponse is the analysis returned by the agent
+type AgentResponse struct {
+	Summary string `json:"summary"`
+
+	// SuppressFor optionally asks the monitor to hold off on the pod for longer than the cooldown, e.g. "24h"
+	SuppressFor string `json:"suppress_for,omitempty"`
+}
+
+// AgentNotifier sends alerts to our Python AI agent service
+type AgentNotifier struct {
+	// url is the agent's base URL and path the AGENT_PATH template appended to it
+	url    string
+	path   *template.Template
+	client *http.Client
+
+	// timeout bounds each attempt; retries and backoff are bounded separately
+	timeout    time.Duration
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	deadline   time.Duration
+
+	// fieldMap renames top-level payload fields for agents expecting other names
+	fieldMap map[string]string
+
+	// headers are extra headers set on every request
+	headers map[string]string
+
+	// statusActions override what a response status means, see statusAction
+	statusActions map[int]AgentAction
+
+	// idempotencyHeader, when set, is the header carrying each alert's idempotency key
+	idempotencyHeader string
+
+	// token, when set, provides the bearer token for each request
+	token *tokenSource
+
+	// slots bound the number of concurrent agent requests
+	slots *agentSlots
+
+	// onResponse, when set, receives every successful agent response body
+	onResponse func(alert *Alert, body []byte)
+}
+
+// NewAgentNotifier creates a notifier for the agent at baseURL
+func NewAgentNotifier(cfg *Config, baseURL string) (*AgentNotifier, error) {
+	path, err := parseAgentPath(cfg.AgentPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AGENT_PATH %q: %w", cfg.AgentPath, err)
+	}
+	return &AgentNotifier{
+		url:        strings.TrimSuffix(baseURL, "/"),
+		path:       path,
+		client:     &http.Client{},
+		timeout:    cfg.AgentTimeout,
+		maxRetries: cfg.AgentMaxRetries,
+		backoff:    cfg.AgentRetryBackoff,
+		maxBackoff: cfg.AgentRetryMaxBackoff,
+		deadline:   cfg.AgentRetryDeadline,
+		fieldMap:   cfg.AgentFieldMap,
+		headers:    cfg.AgentHeaders,
+		token:      newTokenSource(cfg),
+		slots:      newAgentSlots(cfg),
+
+		statusActions:     cfg.AgentStatusActions,
+		idempotencyHeader: cfg.AgentIdempotencyHeader,
+	}, nil
+}
+
+// SetClock implements clockUser; AGENT_MAX_QUEUE_AGE is measured with it
+func (n *AgentNotifier) SetClock(clock Clock) {
+	n.slots.clock = clock
+}
+
+// SetResponseHandler captures the body of every successful agent response
+func (n *AgentNotifier) SetResponseHandler(fn func(alert *Alert, body []byte)) {
+	n.onResponse = fn
+}
+
+// responseCapturer is implemented by notifiers that can hand back the agent's response
+type responseCapturer interface {
+	SetResponseHandler(fn func(alert *Alert, body []byte))
+}
+
+// Name implements Notifier
+func (n *AgentNotifier) Name() string {
+	return "agent"
+}
+
+// Notify implements Notifier
+func (n *AgentNotifier) Notify(ctx context.Context, alert *Alert) error {
+	return n.triggerAnalysis(ctx, alert)
+}
+
+// triggerAnalysis calls our Python AI agent service.
+// Each attempt is bounded by the agent timeout and failed attempts are
+// retried with exponential backoff, so the worst case is
+// (maxRetries+1)*timeout plus the backoff between attempts, and never more
+// than the retry deadline when one is set.
+func (n *AgentNotifier) triggerAnalysis(ctx context.Context, alert *Alert) error {
+	log.Printf("Triggering analysis for pod: %s/%s (Reason: %s)", alert.Namespace, alert.PodName, alert.Reason)
+
+	jsonPayload, err := marshalAlert(alert, n.fieldMap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON for pod %s: %w", alert.PodName, err)
+	}
+
+	ctx, cancel := withRetryDeadline(ctx, n.deadline)
+	defer cancel()
+
+	queued := n.slots.clock.Now()
+	backoff := capBackoff(n.backoff, n.maxBackoff)
+	for attempt := 0; ; attempt++ {
+		retry, err := n.attempt(ctx, alert, jsonPayload, queued)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= n.maxRetries {
+			return err
+		}
+
+		log.Printf("WARNING: Agent attempt %d/%d for pod %s/%s failed, retrying in %v: %v",
+			attempt+1, n.maxRetries+1, alert.Namespace, alert.PodName, backoff, err)
+		if werr := waitRetry(ctx, backoff); werr != nil {
+			return fmt.Errorf("gave up retrying agent for pod %s: %w (last error: %v)", alert.PodName, werr, err)
+		}
+		backoff = capBackoff(backoff*2, n.maxBackoff)
+	}
+}
+
+// attempt sends the payload to the agent once.
+// It reports whether a failure is worth retrying.
+func (n *AgentNotifier) attempt(ctx context.Context, alert *Alert, jsonPayload []byte, queued time.Time) (bool, error) {
+	// Wait for a free agent slot before the agent timeout starts, giving up if
+	// we are shutting down or the alert went stale
+	if err := n.slots.acquire(ctx, queued); err != nil {
+		return !errors.Is(err, errQueueAgeExceeded), fmt.Errorf("gave up waiting for an agent slot for pod %s: %w", alert.PodName, err)
+	}
+	defer n.slots.release()
+
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	url, err := n.requestURL(alert)
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request for pod %s: %w", alert.PodName, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range n.headers {
+		req.Header.Set(name, value)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if n.idempotencyHeader != "" {
+		// The same key on every retry lets the agent ignore a resend after a timed-out success
+		req.Header.Set(n.idempotencyHeader, alert.idempotencyKey())
+	}
+	if n.token != nil {
+		if token := n.token.Token(); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to send request to agent for pod %s: %w", alert.PodName, err)
+	}
+	defer resp.Body.Close()
+
+	// Async agents answer 202 Accepted once the analysis is queued; AGENT_STATUS_ACTIONS covers richer contracts
+	switch n.statusAction(resp.StatusCode) {
+	case AgentActionRetry:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxAgentErrorBytes))
+		log.Printf("Agent error response: %s", string(body))
+		return true, fmt.Errorf("agent service returned status: %s", resp.Status)
+	case AgentActionDrop:
+		// The same request will fail the same way again
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxAgentErrorBytes))
+		log.Printf("ERROR: Agent rejected the alert for pod %s/%s with %s, not retrying: %s",
+			alert.Namespace, alert.PodName, resp.Status, string(body))
+		return false, fmt.Errorf("agent service rejected the alert: %s", resp.Status)
+	}
+
+	log.Printf("Successfully triggered analysis for %s/%s. Agent responded: %s", alert.Namespace, alert.PodName, resp.Status)
+
+	// Only a 200 carries the analysis; 202 and 204 have nothing to capture
+	if n.onResponse != nil && resp.StatusCode == http.StatusOK {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxAgentResponseBytes))
+		if err != nil {
+			log.Printf("WARNING: Failed to read agent response for pod %s/%s: %v", alert.Namespace, alert.PodName, err)
+			return false, nil
+		}
+		n.onResponse(alert, body)
+	}
+	return false, nil
+}
@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// agentPathEscaper is the template function appended to every AGENT_PATH action
+const agentPathEscaper = "_agentPathEscape"
+
+// parseAgentPath parses AGENT_PATH as a template over the Alert, e.g.
+// "/summarize/{{.Namespace}}/{{.Reason}}". Every substituted value is
+// path-escaped, so a value can never add segments or a query to the URL.
+func parseAgentPath(path string) (*template.Template, error) {
+	tmpl, err := template.New("AGENT_PATH").
+		Funcs(template.FuncMap{agentPathEscaper: func(v interface{}) string { return url.PathEscape(fmt.Sprint(v)) }}).
+		Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	escapeActions(tmpl.Tree.Root)
+
+	// Referencing a field the Alert doesn't have only fails on execution
+	var b strings.Builder
+	if err := tmpl.Execute(&b, &Alert{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// escapeActions pipes the output of every action below node through agentPathEscaper
+func escapeActions(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			escapeActions(child)
+		}
+	case *parse.ActionNode:
+		// Actions that only declare a variable print nothing
+		if len(n.Pipe.Decl) > 0 {
+			return
+		}
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pos,
+			Args:     []parse.Node{parse.NewIdentifier(agentPathEscaper).SetPos(n.Pos)},
+		})
+	case *parse.IfNode:
+		escapeActions(n.List)
+		escapeActions(n.ElseList)
+	case *parse.RangeNode:
+		escapeActions(n.List)
+		escapeActions(n.ElseList)
+	case *parse.WithNode:
+		escapeActions(n.List)
+		escapeActions(n.ElseList)
+	}
+}
+
+// requestURL renders the agent URL for the alert
+func (n *AgentNotifier) requestURL(alert *Alert) (string, error) {
+	var b strings.Builder
+	b.WriteString(n.url)
+	if err := n.path.Execute(&b, alert); err != nil {
+		return "", fmt.Errorf("failed to render AGENT_PATH for pod %s: %w", alert.PodName, err)
+	}
+	return b.String(), nil
+}
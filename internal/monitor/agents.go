@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Success policies for delivering an alert to several agents
+const (
+	agentPolicyAll    = "all"
+	agentPolicyAny    = "any"
+	agentPolicyQuorum = "quorum"
+)
+
+// MultiAgentNotifier fans each alert out to redundant agents concurrently.
+// The alert counts as delivered according to the success policy.
+type MultiAgentNotifier struct {
+	agents []*AgentNotifier
+	policy string
+}
+
+// newAgentNotifiers returns the agent notifier for the configured agent URLs
+func newAgentNotifiers(cfg *Config) (Notifier, error) {
+	if cfg.AgentGRPCAddr != "" {
+		return NewGRPCNotifier(cfg)
+	}
+	if len(cfg.AgentURLs) == 0 {
+		n, err := NewAgentNotifier(cfg, cfg.AgentURL)
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
+	}
+	switch cfg.AgentSuccessPolicy {
+	case agentPolicyAll, agentPolicyAny, agentPolicyQuorum:
+	default:
+		return nil, fmt.Errorf("invalid AGENT_SUCCESS_POLICY %q: expected all, any or quorum", cfg.AgentSuccessPolicy)
+	}
+
+	m := &MultiAgentNotifier{policy: cfg.AgentSuccessPolicy}
+	for _, url := range cfg.AgentURLs {
+		n, err := NewAgentNotifier(cfg, url)
+		if err != nil {
+			return nil, err
+		}
+		m.agents = append(m.agents, n)
+	}
+	return m, nil
+}
+
+// Name implements Notifier
+func (m *MultiAgentNotifier) Name() string {
+	return "agent"
+}
+
+// SetClock implements clockUser
+func (m *MultiAgentNotifier) SetClock(clock Clock) {
+	for _, a := range m.agents {
+		a.SetClock(clock)
+	}
+}
+
+// SetResponseHandler implements responseCapturer
+func (m *MultiAgentNotifier) SetResponseHandler(fn func(alert *Alert, body []byte)) {
+	for _, a := range m.agents {
+		a.SetResponseHandler(fn)
+	}
+}
+
+// Notify implements Notifier
+func (m *MultiAgentNotifier) Notify(ctx context.Context, alert *Alert) error {
+	errs := make([]error, len(m.agents))
+	var wg sync.WaitGroup
+	for i, a := range m.agents {
+		wg.Add(1)
+		go func(i int, a *AgentNotifier) {
+			defer wg.Done()
+			if err := a.Notify(ctx, alert); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", a.url, err)
+			}
+		}(i, a)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	if succeeded >= m.required() {
+		return nil
+	}
+	return fmt.Errorf("%d of %d agents accepted the alert, policy %q needs %d: %w",
+		succeeded, len(m.agents), m.policy, m.required(), errors.Join(errs...))
+}
+
+// required returns how many agents must accept an alert under the policy
+func (m *MultiAgentNotifier) required() int {
+	switch m.policy {
+	case agentPolicyAny:
+		return 1
+	case agentPolicyQuorum:
+		return len(m.agents)/2 + 1
+	default:
+		return len(m.agents)
+	}
+}
@@ -0,0 +1,130 @@
+package monitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Alert describes a pod (or workload) failure sent to the agent
+type Alert struct {
+	// Environment is the ENVIRONMENT the monitor runs in, e.g. prod
+	Environment string `json:"environment,omitempty"`
+
+	// StaticLabels are the deployment-wide STATIC_LABELS, e.g. region and team
+	StaticLabels map[string]string `json:"static_labels,omitempty"`
+
+	Namespace string `json:"namespace"`
+	PodName   string `json:"pod_name"`
+	NodeName  string `json:"node_name,omitempty"`
+
+	// OwnerKind and OwnerName identify the workload, e.g. Deployment/api.
+	// Workload-level alerts have no PodName.
+	OwnerKind string `json:"owner_kind,omitempty"`
+	OwnerName string `json:"owner_name,omitempty"`
+
+	// Labels are the pod's identity labels, e.g. app.kubernetes.io/instance
+	Labels map[string]string `json:"labels,omitempty"`
+
+	Reason   string    `json:"reason"`
+	Severity Severity  `json:"severity"`
+	Kind     AlertKind `json:"kind"`
+
+	// Detail is the message behind the reason, e.g. why an image pull failed
+	Detail string `json:"detail,omitempty"`
+
+	// Container is the failing container and Image the image its spec asks for
+	Container string `json:"container,omitempty"`
+	Image     string `json:"image,omitempty"`
+
+	// FailedSince is the best available estimate of when the failure started
+	FailedSince *time.Time `json:"failed_since,omitempty"`
+
+	// AffectedPods lists the namespace/name of every pod folded into a node or correlation alert
+	AffectedPods []string `json:"affected_pods,omitempty"`
+
+	// Test marks a synthetic alert sent by --test-notifiers
+	Test bool `json:"test,omitempty"`
+
+	// Resources are the requests and limits of the failing container(s)
+	Resources []ContainerResources `json:"resources,omitempty"`
+
+	// Events and Logs are optional enrichment, left empty when disabled or unavailable
+	Events []AlertEvent `json:"events,omitempty"`
+	Logs   string       `json:"logs,omitempty"`
+
+	// PartiallyEnriched is set when ENRICHMENT_TIMEOUT cut enrichment short
+	PartiallyEnriched bool `json:"partially_enriched,omitempty"`
+
+	// Truncated lists the fields that were cut to fit MAX_PAYLOAD_BYTES
+	Truncated []string `json:"truncated,omitempty"`
+
+	// podUID tells apart pods recreated under the same name
+	podUID string
+
+	// cacheKey is the alert cache entry the alert was deduplicated on
+	cacheKey string
+
+	// occurredAt tells apart alerts that are otherwise the same, e.g. a repeat
+	// after the cooldown; it is the reservation time for deduplicated alerts
+	occurredAt time.Time
+
+	// routes, when set, are the only notifiers that receive the alert
+	routes map[string]bool
+
+	// audit is the decision record checkAndTrigger made for the alert, for AUDIT_SINK
+	audit *auditRecord
+
+	// agentResponse holds a captured agent response until the alert is recorded as sent; guarded by historyMu
+	agentResponse []byte
+}
+
+// AlertKind tells a first alert for a pod apart from later ones
+type AlertKind string
+
+const (
+	// AlertKindFirst is the first alert for the pod
+	AlertKindFirst AlertKind = "first"
+	// AlertKindRepeat re-alerts the same reason after the cooldown expired
+	AlertKindRepeat AlertKind = "repeat"
+	// AlertKindReasonChanged follows an earlier alert for a different reason
+	AlertKindReasonChanged AlertKind = "reason-changed"
+	// AlertKindChronic escalates a pod that has been bad for longer than CHRONIC_FAILURE_AFTER
+	AlertKindChronic AlertKind = "chronic"
+	// AlertKindResolved reports that an alerted pod recovered
+	AlertKindResolved AlertKind = "resolved"
+)
+
+// AlertEvent is a Kubernetes event recorded against the failing pod
+type AlertEvent struct {
+	Type     string    `json:"type"`
+	Reason   string    `json:"reason"`
+	Message  string    `json:"message"`
+	Count    int32     `json:"count,omitempty"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// ContainerResources are the configured cpu/memory requests and limits of a container
+type ContainerResources struct {
+	Container string            `json:"container"`
+	Requests  map[string]string `json:"requests,omitempty"`
+	Limits    map[string]string `json:"limits,omitempty"`
+}
+
+// idempotencyKey is a stable hash of what makes the alert one occurrence:
+// the pod, its reason and kind, when the failure started and when the alert
+// was raised. Retries of the same alert produce the same key; a repeat after
+// the cooldown does not.
+func (a *Alert) idempotencyKey() string {
+	var since string
+	if a.FailedSince != nil {
+		since = a.FailedSince.UTC().Format(time.RFC3339Nano)
+	}
+	occurred := a.occurredAt.UTC().Format(time.RFC3339Nano)
+	h := sha256.New()
+	for _, part := range []string{a.Namespace, a.PodName, a.podUID, a.OwnerKind, a.OwnerName, a.Reason, string(a.Kind), since, occurred} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
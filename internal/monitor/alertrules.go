@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// podAlertRuleResource is the PodAlertRule custom resource, see configs/podalertrule-crd.yaml
+var podAlertRuleResource = schema.GroupVersionResource{
+	Group:    "watch-my-pod.io",
+	Version:  "v1alpha1",
+	Resource: "podalertrules",
+}
+
+// PodAlertRule is a namespaced rule tuning how failures of matching pods are alerted on
+type PodAlertRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PodAlertRuleSpec `json:"spec"`
+}
+
+// PodAlertRuleSpec selects pods and failure reasons and says how to alert on them
+type PodAlertRuleSpec struct {
+	// Selector picks the pods of the rule's namespace it applies to; empty selects all
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Reasons limits the rule to these failure reasons; empty applies it to all
+	Reasons []string `json:"reasons,omitempty"`
+
+	// Ignore drops matching alerts
+	Ignore bool `json:"ignore,omitempty"`
+
+	// Cooldown and Severity override the configured ones for matching alerts
+	Cooldown string `json:"cooldown,omitempty"`
+	Severity string `json:"severity,omitempty"`
+}
+
+// alertRule is a parsed PodAlertRule
+type alertRule struct {
+	namespace, name string
+
+	selector labels.Selector
+	reasons  map[string]bool
+	ignore   bool
+	cooldown time.Duration
+	severity Severity
+}
+
+// String describes the rule for logs
+func (r *alertRule) String() string {
+	return r.namespace + "/" + r.name
+}
+
+// matches reports whether the rule applies to the pod failing with reason
+func (r *alertRule) matches(pod *corev1.Pod, reason string) bool {
+	if pod.Namespace != r.namespace || !r.selector.Matches(labels.Set(pod.Labels)) {
+		return false
+	}
+	return len(r.reasons) == 0 || r.reasons[reason]
+}
+
+// parseAlertRule converts a PodAlertRule object, validating its spec
+func parseAlertRule(obj *unstructured.Unstructured) (*alertRule, error) {
+	var pr PodAlertRule
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pr); err != nil {
+		return nil, err
+	}
+
+	r := &alertRule{namespace: pr.Namespace, name: pr.Name, ignore: pr.Spec.Ignore, selector: labels.Everything()}
+	if pr.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(pr.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector: %w", err)
+		}
+		r.selector = selector
+	}
+	if len(pr.Spec.Reasons) > 0 {
+		r.reasons = make(map[string]bool, len(pr.Spec.Reasons))
+		for _, reason := range pr.Spec.Reasons {
+			r.reasons[reason] = true
+		}
+	}
+	if pr.Spec.Cooldown != "" {
+		d, err := time.ParseDuration(pr.Spec.Cooldown)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid cooldown %q", pr.Spec.Cooldown)
+		}
+		r.cooldown = d
+	}
+	if pr.Spec.Severity != "" {
+		sev, err := ParseSeverity(pr.Spec.Severity)
+		if err != nil {
+			return nil, err
+		}
+		r.severity = sev
+	}
+	return r, nil
+}
+
+// watchAlertRules adds an informer on the PodAlertRules of namespace
+func (c *Controller) watchAlertRules(namespace string) {
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(c.dynamic, c.cfg.ResyncPeriod, namespace, nil)
+	informer := factory.ForResource(podAlertRuleResource).Informer()
+	reload := func(interface{}) { c.reloadAlertRules() }
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    reload,
+		UpdateFunc: func(_, newObj interface{}) { reload(newObj) },
+		DeleteFunc: reload,
+	})
+	c.ruleInformers = append(c.ruleInformers, informer)
+	c.auxInformers = append(c.auxInformers, informer)
+}
+
+// reloadAlertRules rebuilds the rule set from the informer stores, skipping invalid rules
+func (c *Controller) reloadAlertRules() {
+	var rules []*alertRule
+	for _, inf := range c.ruleInformers {
+		for _, obj := range inf.GetStore().List() {
+			u, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			r, err := parseAlertRule(u)
+			if err != nil {
+				log.Printf("ERROR: Ignoring invalid PodAlertRule %s/%s: %v", u.GetNamespace(), u.GetName(), err)
+				continue
+			}
+			rules = append(rules, r)
+		}
+	}
+	// Rules are tried in name order, so the outcome doesn't depend on list order
+	sort.Slice(rules, func(i, j int) bool { return rules[i].String() < rules[j].String() })
+	c.alertRules.Store(&rules)
+}
+
+// matchAlertRule returns the first PodAlertRule matching the pod, or nil
+func (c *Controller) matchAlertRule(pod *corev1.Pod, reason string) *alertRule {
+	rules := c.alertRules.Load()
+	if rules == nil {
+		return nil
+	}
+	for _, r := range *rules {
+		if r.matches(pod, reason) {
+			return r
+		}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Audit decisions
+const (
+	// auditSent is an alert delivered by at least one notifier
+	auditSent = "sent"
+	// auditNotDelivered is an alert every notifier failed to deliver
+	auditNotDelivered = "not_delivered"
+	// auditSuppressed is an alert held back by a rule, a maintenance window or the cooldown
+	auditSuppressed = "suppressed"
+	// auditIgnored is a bad state not alerted on by a filter such as MIN_POD_AGE
+	auditIgnored = "ignored"
+	// auditFolded is a pod alert folded into a node or correlation alert
+	auditFolded = "folded"
+	// auditDropped is an alert no notifier was routed to take
+	auditDropped = "dropped"
+)
+
+// auditRecord is one alerting decision, written as a line of JSON
+type auditRecord struct {
+	At        time.Time `json:"at"`
+	Namespace string    `json:"namespace"`
+	PodName   string    `json:"pod_name,omitempty"`
+	Owner     string    `json:"owner,omitempty"`
+	Reason    string    `json:"reason"`
+	Decision  string    `json:"decision"`
+
+	// Rule names the filter or rule the decision came from, e.g. MIN_POD_AGE or a PodAlertRule
+	Rule   string `json:"rule,omitempty"`
+	Detail string `json:"detail,omitempty"`
+
+	Kind     AlertKind `json:"kind,omitempty"`
+	Severity Severity  `json:"severity,omitempty"`
+	Cooldown string    `json:"cooldown,omitempty"`
+
+	// Notifiers received the alert; FailedNotifiers were eligible but failed
+	Notifiers       []string `json:"notifiers,omitempty"`
+	FailedNotifiers []string `json:"failed_notifiers,omitempty"`
+}
+
+// auditQueueSize bounds the records waiting to be written to AUDIT_SINK
+const auditQueueSize = 1024
+
+// AuditLog appends a JSON line to AUDIT_SINK for every alerting decision, kept
+// apart from the operational logs so it can be retained and reviewed on its own.
+// Records are written and synced by a background goroutine, so a slow disk
+// never holds up the informer handlers.
+type AuditLog struct {
+	out io.Writer
+	// file is set when the sink is a file we opened, and is synced after every batch of records
+	file *os.File
+
+	records chan []byte
+	done    chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+	// last is the latest pod-level decision per pod and reason, so a pod held
+	// back the same way on every recheck is recorded once
+	last map[string]map[string]string
+}
+
+// NewAuditLog opens the AUDIT_SINK configured in cfg: stdout or a file appended to
+func NewAuditLog(cfg *Config) (*AuditLog, error) {
+	a := &AuditLog{
+		out:     os.Stdout,
+		records: make(chan []byte, auditQueueSize),
+		done:    make(chan struct{}),
+		last:    make(map[string]map[string]string),
+	}
+	if cfg.AuditSink != "stdout" {
+		f, err := os.OpenFile(cfg.AuditSink, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit sink %s: %w", cfg.AuditSink, err)
+		}
+		a.out, a.file = f, f
+	}
+	go a.run()
+	return a, nil
+}
+
+// write queues the record for the writer goroutine
+func (a *AuditLog) write(rec *auditRecord) {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		log.Printf("WARNING: Failed to marshal audit record for %s/%s: %v", rec.Namespace, rec.PodName, err)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return
+	}
+	select {
+	case a.records <- append(line, '\n'):
+	default:
+		log.Printf("ERROR: Audit sink is falling behind, dropped the record for %s/%s", rec.Namespace, rec.PodName)
+	}
+}
+
+// run writes queued records until Close, syncing the file once per batch
+func (a *AuditLog) run() {
+	defer close(a.done)
+	for line := range a.records {
+		a.writeLine(line)
+		// Drain what queued up meanwhile before paying for a sync
+		for pending := len(a.records); pending > 0; pending-- {
+			a.writeLine(<-a.records)
+		}
+		if a.file != nil {
+			if err := a.file.Sync(); err != nil {
+				log.Printf("ERROR: Failed to sync audit sink: %v", err)
+			}
+		}
+	}
+}
+
+// writeLine appends one record to the sink
+func (a *AuditLog) writeLine(line []byte) {
+	if _, err := a.out.Write(line); err != nil {
+		log.Printf("ERROR: Failed to write audit record: %v", err)
+	}
+}
+
+// changed records decision as the latest for the pod's reason, reporting whether it differs from the previous one
+func (a *AuditLog) changed(podKey, reason, decision string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	reasons, ok := a.last[podKey]
+	if !ok {
+		reasons = make(map[string]string)
+		a.last[podKey] = reasons
+	}
+	if reasons[reason] == decision {
+		return false
+	}
+	reasons[reason] = decision
+	return true
+}
+
+// forget drops the latest decisions for the pod, so the next one is recorded
+func (a *AuditLog) forget(podKey string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.last, podKey)
+}
+
+// Close writes the queued records and closes the sink if it is a file
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.records)
+	}
+	a.mu.Unlock()
+	<-a.done
+	if a.file == nil {
+		return nil
+	}
+	return a.file.Close()
+}
+
+// auditPod records a decision not to send an alert for the pod, if it differs
+// from the last one recorded for the pod
+func (c *Controller) auditPod(pod *corev1.Pod, reason, decision, rule, detail string) {
+	if c.audit == nil {
+		return
+	}
+	if !c.audit.changed(pod.Namespace+"/"+pod.Name, reason, decision+"|"+rule) {
+		return
+	}
+	kind, name := podOwner(pod)
+	rec := &auditRecord{
+		Namespace: pod.Namespace,
+		PodName:   pod.Name,
+		Reason:    reason,
+		Decision:  decision,
+		Rule:      rule,
+		Detail:    detail,
+	}
+	if name != "" {
+		rec.Owner = kind + "/" + name
+	}
+	c.writeAudit(rec)
+}
+
+// auditAlert records the outcome of handing an alert to the notifiers, on top
+// of the decision record checkAndTrigger attached to it, if any
+func (c *Controller) auditAlert(alert *Alert, decision string, received, failed []string) {
+	if c.audit == nil {
+		return
+	}
+	rec := alert.audit
+	if rec == nil {
+		rec = &auditRecord{}
+	}
+	rec.Namespace = alert.Namespace
+	rec.PodName = alert.PodName
+	if alert.PodName != "" {
+		// The next hold-back after an alert is news again
+		c.audit.forget(alert.Namespace + "/" + alert.PodName)
+	}
+	if alert.OwnerName != "" {
+		rec.Owner = alert.OwnerKind + "/" + alert.OwnerName
+	}
+	rec.Reason = alert.Reason
+	rec.Kind = alert.Kind
+	rec.Severity = alert.Severity
+	rec.Decision = decision
+	rec.Notifiers = received
+	rec.FailedNotifiers = failed
+	if decision == auditDropped {
+		rec.Rule = "NOTIFIER_ROUTES"
+	}
+	c.writeAudit(rec)
+}
+
+// auditFold records that buffered pod alerts were folded into the alert for group
+func (c *Controller) auditFold(group []pendingAlert, into string) {
+	if c.audit == nil {
+		return
+	}
+	for _, p := range group {
+		if p.alert.audit == nil {
+			p.alert.audit = &auditRecord{}
+		}
+		p.alert.audit.Detail = "folded into " + into
+		c.auditAlert(p.alert, auditFolded, nil, nil)
+	}
+}
+
+// forgetAudit drops the pod's last recorded decision once it recovered or was deleted
+func (c *Controller) forgetAudit(podKey string) {
+	if c.audit != nil {
+		c.audit.forget(podKey)
+	}
+}
+
+// writeAudit stamps and writes the record
+func (c *Controller) writeAudit(rec *auditRecord) {
+	rec.At = c.clock.Now().UTC()
+	c.audit.write(rec)
+}
@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ParseBenignTerminations parses BENIGN_TERMINATIONS, a semicolon-separated
+// list of regular expressions (semicolons because patterns often contain commas)
+func ParseBenignTerminations(spec string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	for _, p := range strings.Split(spec, ";") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BENIGN_TERMINATIONS pattern %q: %w", p, err)
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns, nil
+}
+
+// benignTermination reports whether a container termination is a known-harmless
+// exit: its reason is one of IGNORE_TERMINATED_REASONS, or its reason or message
+// matches one of BENIGN_TERMINATIONS
+func (c *Controller) benignTermination(t *corev1.ContainerStateTerminated) bool {
+	return c.benignTerminationRule(t) != ""
+}
+
+// benignTerminationRule names the setting that makes a termination benign, or is empty
+func (c *Controller) benignTerminationRule(t *corev1.ContainerStateTerminated) string {
+	if t == nil {
+		return ""
+	}
+	for _, reason := range c.cfg.IgnoreTerminatedReasons {
+		if t.Reason == reason {
+			return "IGNORE_TERMINATED_REASONS"
+		}
+	}
+	for _, re := range c.cfg.BenignTerminations {
+		if re.MatchString(t.Reason) || (t.Message != "" && re.MatchString(t.Message)) {
+			return "BENIGN_TERMINATIONS"
+		}
+	}
+	return ""
+}
+
+// benignPodFailure reports whether a failed pod only failed through known-harmless
+// exits: it has terminated containers and every one of them is a benign termination
+func (c *Controller) benignPodFailure(pod *corev1.Pod) bool {
+	terminated := 0
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			if status.State.Terminated == nil {
+				continue
+			}
+			if !c.benignTermination(status.State.Terminated) {
+				return false
+			}
+			terminated++
+		}
+	}
+	return terminated > 0
+}
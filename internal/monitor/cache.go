@@ -0,0 +1,313 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// alertCacheRetention is how long a cache entry outlives its suppression,
+// so a re-alert after the cooldown is still recognized as a repeat
+const alertCacheRetention = 24 * time.Hour
+
+// AlertCacheEntry is the last alert sent for a pod (or workload)
+type AlertCacheEntry struct {
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason"`
+
+	// Until is when the entry stops suppressing new alerts
+	Until time.Time `json:"until"`
+}
+
+// AlertCache stores the last alert sent per key, deciding which alerts are duplicates.
+// Implementations must be safe for concurrent use.
+type AlertCache interface {
+	// ShouldAlert reports whether an alert for reason may be sent for key, and its kind
+	ShouldAlert(ctx context.Context, key, reason string) (bool, AlertKind, error)
+
+	// Record stores an alert for key, suppressing further alerts for ttl
+	Record(ctx context.Context, key, reason string, ttl time.Duration) error
+
+	// Reserve decides like ShouldAlert and, if the alert may be sent, records it
+	// like Record, as one atomic step: of concurrent callers for the same key,
+	// at most one wins
+	Reserve(ctx context.Context, key, reason string, ttl time.Duration) (Reservation, error)
+
+	// Release undoes a won reservation, restoring the entry it replaced, unless
+	// key has been written again since
+	Release(ctx context.Context, key string, res Reservation) error
+
+	// Get returns the last alert recorded for key, if it is still retained
+	Get(ctx context.Context, key string) (AlertCacheEntry, bool, error)
+
+	// Clear forgets key
+	Clear(ctx context.Context, key string) error
+
+	// Range calls fn for every retained entry until fn returns false
+	Range(ctx context.Context, fn func(key string, entry AlertCacheEntry) bool) error
+}
+
+// Reservation is the outcome of AlertCache.Reserve
+type Reservation struct {
+	// Won is set if the caller may send the alert; the entry is then recorded
+	Won  bool
+	Kind AlertKind
+
+	// Last is the entry the key had before, if Existed, so a failed delivery can restore it
+	Last    AlertCacheEntry
+	Existed bool
+
+	// Entry is the entry recorded for a won reservation
+	Entry AlertCacheEntry
+}
+
+// sameEntry reports whether two cache entries are the same write
+func sameEntry(a, b AlertCacheEntry) bool {
+	return a.At.Equal(b.At) && a.Reason == b.Reason
+}
+
+// decideAlert applies the deduplication rules to the last entry for a key
+func decideAlert(last AlertCacheEntry, exists bool, now time.Time, reason string) (bool, AlertKind) {
+	switch {
+	case !exists:
+		return true, AlertKindFirst
+	case last.Reason == crashLoopWarningReason && reason != crashLoopWarningReason:
+		// The early warning must not hold back the page it warned about
+		return true, AlertKindReasonChanged
+	case now.Before(last.Until):
+		return false, ""
+	case last.Reason != reason:
+		return true, AlertKindReasonChanged
+	default:
+		return true, AlertKindRepeat
+	}
+}
+
+// newCacheEntry builds the entry Record stores, and how long to retain it
+func newCacheEntry(now time.Time, reason string, ttl time.Duration) (AlertCacheEntry, time.Duration) {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return AlertCacheEntry{At: now, Reason: reason, Until: now.Add(ttl)}, ttl + alertCacheRetention
+}
+
+// memoryAlertCache is the default, per-process AlertCache
+type memoryAlertCache struct {
+	clock Clock
+	limit *trackingLimit
+
+	mu      sync.Mutex
+	entries map[string]AlertCacheEntry
+}
+
+// errAlertCacheFull is returned by Record and Reserve for a new key once MAX_ALERT_CACHE_ENTRIES is reached
+var errAlertCacheFull = errors.New("alert cache is full (MAX_ALERT_CACHE_ENTRIES)")
+
+// newMemoryAlertCache returns an empty in-memory cache holding at most maxEntries keys; 0 is unlimited
+func newMemoryAlertCache(clock Clock, maxEntries int) *memoryAlertCache {
+	return &memoryAlertCache{
+		clock:   clock,
+		limit:   &trackingLimit{set: "alert_cache", max: maxEntries},
+		entries: make(map[string]AlertCacheEntry),
+	}
+}
+
+// retained reports whether an entry is still kept at now
+func retained(entry AlertCacheEntry, now time.Time) bool {
+	return now.Before(entry.Until.Add(alertCacheRetention))
+}
+
+// ShouldAlert implements AlertCache
+func (m *memoryAlertCache) ShouldAlert(ctx context.Context, key, reason string) (bool, AlertKind, error) {
+	last, ok, _ := m.Get(ctx, key)
+	should, kind := decideAlert(last, ok, m.clock.Now(), reason)
+	return should, kind, nil
+}
+
+// Record implements AlertCache
+func (m *memoryAlertCache) Record(_ context.Context, key, reason string, ttl time.Duration) error {
+	entry, _ := newCacheEntry(m.clock.Now(), reason, ttl)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.entries[key]; !ok && !m.limit.admit(len(m.entries)) {
+		// Make room from expired entries before refusing the key
+		m.evictExpired()
+		if !m.limit.admit(len(m.entries)) {
+			return errAlertCacheFull
+		}
+	}
+	m.entries[key] = entry
+	return nil
+}
+
+// evictExpired drops entries that are no longer retained; m.mu must be held
+func (m *memoryAlertCache) evictExpired() {
+	now := m.clock.Now()
+	for key, entry := range m.entries {
+		if !retained(entry, now) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// Reserve implements AlertCache under the cache's lock
+func (m *memoryAlertCache) Reserve(_ context.Context, key, reason string, ttl time.Duration) (Reservation, error) {
+	now := m.clock.Now()
+	entry, _ := newCacheEntry(now, reason, ttl)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	last, ok := m.entries[key]
+	if ok && !retained(last, now) {
+		delete(m.entries, key)
+		last, ok = AlertCacheEntry{}, false
+	}
+	res := Reservation{Last: last, Existed: ok, Entry: entry}
+	if res.Won, res.Kind = decideAlert(last, ok, now, reason); !res.Won {
+		return res, nil
+	}
+	if !ok && !m.limit.admit(len(m.entries)) {
+		m.evictExpired()
+		if !m.limit.admit(len(m.entries)) {
+			// An alert that can't be deduplicated would repeat on every event
+			return Reservation{}, errAlertCacheFull
+		}
+	}
+	m.entries[key] = entry
+	return res, nil
+}
+
+// Release implements AlertCache under the cache's lock
+func (m *memoryAlertCache) Release(_ context.Context, key string, res Reservation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if current, ok := m.entries[key]; !ok || !sameEntry(current, res.Entry) {
+		return nil
+	}
+	if res.Existed {
+		m.entries[key] = res.Last
+	} else {
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+// Get implements AlertCache
+func (m *memoryAlertCache) Get(_ context.Context, key string) (AlertCacheEntry, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[key]
+	if ok && !retained(entry, m.clock.Now()) {
+		delete(m.entries, key)
+		return AlertCacheEntry{}, false, nil
+	}
+	return entry, ok, nil
+}
+
+// Clear implements AlertCache
+func (m *memoryAlertCache) Clear(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+// Range implements AlertCache, evicting expired entries as it goes
+func (m *memoryAlertCache) Range(_ context.Context, fn func(key string, entry AlertCacheEntry) bool) error {
+	m.mu.Lock()
+	now := m.clock.Now()
+	snapshot := make(map[string]AlertCacheEntry, len(m.entries))
+	for key, entry := range m.entries {
+		if !retained(entry, now) {
+			delete(m.entries, key)
+			continue
+		}
+		snapshot[key] = entry
+	}
+	m.mu.Unlock()
+
+	for key, entry := range snapshot {
+		if !fn(key, entry) {
+			break
+		}
+	}
+	return nil
+}
+
+// shouldAlert asks the cache whether to alert for key.
+// Cache errors are logged and treated as a first alert, so a cache outage never drops alerts.
+func (c *Controller) shouldAlert(key, reason string) (bool, AlertKind) {
+	should, kind, err := c.alertCache.ShouldAlert(c.ctx, key, reason)
+	if err != nil {
+		log.Printf("WARNING: Failed to read alert cache for %s, deduplication is skipped: %v", key, err)
+		return true, AlertKindFirst
+	}
+	return should, kind
+}
+
+// reserveAlert reserves the cache entry for an alert on key, so concurrent events
+// for the same key can't both send it. Cache errors are logged and treated as a
+// first alert, like shouldAlert. New keys are suppressed while the cache is full.
+func (c *Controller) reserveAlert(key, reason string, ttl time.Duration) Reservation {
+	res, err := c.alertCache.Reserve(c.ctx, key, reason, ttl)
+	if errors.Is(err, errAlertCacheFull) {
+		// Already logged once when the cache filled up
+		return Reservation{}
+	}
+	if err != nil {
+		log.Printf("WARNING: Failed to reserve alert cache entry for %s, deduplication is skipped: %v", key, err)
+		return Reservation{Won: true, Kind: AlertKindFirst}
+	}
+	return res
+}
+
+// dedupDecision is the watchmypod_dedup_decisions_total label for a shouldAlert outcome
+func dedupDecision(should bool, kind AlertKind) string {
+	switch {
+	case !should:
+		return "suppressed"
+	case kind == AlertKindReasonChanged:
+		return "reason_changed"
+	default:
+		return "sent"
+	}
+}
+
+// cacheGet looks up the last alert for key, treating cache errors as a miss
+func (c *Controller) cacheGet(key string) (AlertCacheEntry, bool) {
+	entry, ok, err := c.alertCache.Get(c.ctx, key)
+	if err != nil {
+		log.Printf("WARNING: Failed to read alert cache for %s: %v", key, err)
+		return AlertCacheEntry{}, false
+	}
+	return entry, ok
+}
+
+// cacheRecord records an alert for key, suppressing further ones for ttl
+func (c *Controller) cacheRecord(key, reason string, ttl time.Duration) {
+	if err := c.alertCache.Record(c.ctx, key, reason, ttl); err != nil {
+		if errors.Is(err, errAlertCacheFull) {
+			// Already logged once when the cache filled up
+			return
+		}
+		log.Printf("WARNING: Failed to write alert cache for %s: %v", key, err)
+	}
+}
+
+// cacheClear forgets the last alert for key
+func (c *Controller) cacheClear(key string) {
+	if err := c.alertCache.Clear(c.ctx, key); err != nil {
+		log.Printf("WARNING: Failed to clear alert cache entry for %s: %v", key, err)
+	}
+}
+
+// cacheUndo restores the cache after an alert reserved for key was not delivered,
+// leaving it alone if a newer alert has already replaced the reservation
+func (c *Controller) cacheUndo(key string, res Reservation) {
+	if err := c.alertCache.Release(c.ctx, key, res); err != nil {
+		log.Printf("WARNING: Failed to release alert cache entry for %s: %v", key, err)
+	}
+}
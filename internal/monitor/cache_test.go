@@ -0,0 +1,230 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDecideAlert(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	entry := func(reason string, until time.Time) AlertCacheEntry {
+		return AlertCacheEntry{At: now.Add(-time.Hour), Reason: reason, Until: until}
+	}
+
+	tests := []struct {
+		name   string
+		last   AlertCacheEntry
+		exists bool
+		reason string
+		want   bool
+		kind   AlertKind
+	}{
+		{name: "no entry", reason: "OOMKilled", want: true, kind: AlertKindFirst},
+		{name: "within cooldown", last: entry("OOMKilled", now.Add(time.Minute)), exists: true, reason: "OOMKilled"},
+		{name: "reason change within cooldown", last: entry("OOMKilled", now.Add(time.Minute)), exists: true, reason: "CrashLoopBackOff"},
+		{name: "cooldown ends at until", last: entry("OOMKilled", now), exists: true, reason: "OOMKilled", want: true, kind: AlertKindRepeat},
+		{name: "after cooldown", last: entry("OOMKilled", now.Add(-time.Minute)), exists: true, reason: "OOMKilled", want: true, kind: AlertKindRepeat},
+		{name: "reason change after cooldown", last: entry("OOMKilled", now.Add(-time.Minute)), exists: true, reason: "CrashLoopBackOff", want: true, kind: AlertKindReasonChanged},
+		{name: "warning repeat within cooldown", last: entry(crashLoopWarningReason, now.Add(time.Minute)), exists: true, reason: crashLoopWarningReason},
+		{name: "escalation out of warning", last: entry(crashLoopWarningReason, now.Add(time.Minute)), exists: true, reason: "CrashLoopBackOff", want: true, kind: AlertKindReasonChanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, kind := decideAlert(tt.last, tt.exists, now, tt.reason)
+			if got != tt.want || kind != tt.kind {
+				t.Errorf("decideAlert() = %v, %q, want %v, %q", got, kind, tt.want, tt.kind)
+			}
+		})
+	}
+}
+
+func TestMemoryAlertCacheReserveConcurrent(t *testing.T) {
+	cache := newMemoryAlertCache(NewFakeClock(time.Now()), 0)
+
+	const callers = 50
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	won := 0
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := cache.Reserve(context.Background(), "default/web", "OOMKilled", time.Hour)
+			if err != nil {
+				t.Errorf("Reserve() error = %v", err)
+				return
+			}
+			if res.Won {
+				mu.Lock()
+				won++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if won != 1 {
+		t.Errorf("%d of %d concurrent reservations won, want 1", won, callers)
+	}
+}
+
+func TestTrackingLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		max   int
+		size  int
+		admit bool
+	}{
+		{name: "unlimited", max: 0, size: 1000, admit: true},
+		{name: "below cap", max: 3, size: 2, admit: true},
+		{name: "at cap", max: 3, size: 3, admit: false},
+		{name: "above cap", max: 3, size: 4, admit: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := "test_" + tt.name
+			l := &trackingLimit{set: set, max: tt.max}
+			before := testutil.ToFloat64(trackingRejections.WithLabelValues(set))
+			if got := l.admit(tt.size); got != tt.admit {
+				t.Errorf("admit(%d) = %v, want %v", tt.size, got, tt.admit)
+			}
+			rejected := testutil.ToFloat64(trackingRejections.WithLabelValues(set)) - before
+			if want := map[bool]float64{true: 0, false: 1}[tt.admit]; rejected != want {
+				t.Errorf("counted %v rejections, want %v", rejected, want)
+			}
+			if l.full.Load() == tt.admit {
+				t.Errorf("full = %v after admit() = %v", l.full.Load(), tt.admit)
+			}
+		})
+	}
+}
+
+func TestTrackingLimitRecovers(t *testing.T) {
+	l := &trackingLimit{set: "test_recovers", max: 2}
+	if l.admit(2) {
+		t.Fatal("admit(2) at a cap of 2 = true")
+	}
+	if !l.admit(1) || l.full.Load() {
+		t.Error("the limit did not recover once the set shrank below its cap")
+	}
+}
+
+func TestMemoryAlertCacheLimit(t *testing.T) {
+	ctx := context.Background()
+	cache := newMemoryAlertCache(NewFakeClock(time.Now()), 2)
+	for i := 0; i < 2; i++ {
+		if err := cache.Record(ctx, fmt.Sprintf("default/pod-%d", i), "OOMKilled", time.Hour); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	if err := cache.Record(ctx, "default/new", "OOMKilled", time.Hour); !errors.Is(err, errAlertCacheFull) {
+		t.Errorf("Record() of a new key in a full cache = %v, want errAlertCacheFull", err)
+	}
+	if res, err := cache.Reserve(ctx, "default/new", "OOMKilled", time.Hour); !errors.Is(err, errAlertCacheFull) || res.Won {
+		t.Errorf("Reserve() of a new key in a full cache = %+v, %v, want no win and errAlertCacheFull", res, err)
+	}
+	if _, ok, _ := cache.Get(ctx, "default/new"); ok {
+		t.Error("a refused key was stored")
+	}
+
+	// Keys already cached are still updated
+	if err := cache.Record(ctx, "default/pod-0", "CrashLoopBackOff", time.Hour); err != nil {
+		t.Errorf("Record() of a cached key in a full cache error = %v", err)
+	}
+	if entry, _, _ := cache.Get(ctx, "default/pod-0"); entry.Reason != "CrashLoopBackOff" {
+		t.Errorf("cached key reason = %q, want CrashLoopBackOff", entry.Reason)
+	}
+}
+
+func TestMemoryAlertCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	cache := newMemoryAlertCache(clock, 0)
+
+	if res, _ := cache.Reserve(ctx, "default/web", "OOMKilled", time.Hour); !res.Won || res.Kind != AlertKindFirst {
+		t.Fatalf("first Reserve() = %+v, want a first alert", res)
+	}
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		want    bool
+		kind    AlertKind
+	}{
+		{name: "within cooldown", advance: 30 * time.Minute},
+		{name: "after cooldown", advance: 30 * time.Minute, want: true, kind: AlertKindRepeat},
+		// The repeat starts a new cooldown, and the entry is forgotten once its retention ends
+		{name: "after retention", advance: time.Hour + alertCacheRetention, want: true, kind: AlertKindFirst},
+	}
+	for _, step := range steps {
+		clock.Advance(step.advance)
+		res, err := cache.Reserve(ctx, "default/web", "OOMKilled", time.Hour)
+		if err != nil {
+			t.Fatalf("%s: Reserve() error = %v", step.name, err)
+		}
+		if res.Won != step.want || res.Kind != step.kind {
+			t.Errorf("%s: Reserve() = %v, %q, want %v, %q", step.name, res.Won, res.Kind, step.want, step.kind)
+		}
+	}
+}
+
+func TestMemoryAlertCacheReasonChange(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	cache := newMemoryAlertCache(clock, 0)
+
+	if err := cache.Record(ctx, "default/web", "OOMKilled", time.Hour); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if should, _, _ := cache.ShouldAlert(ctx, "default/web", "CrashLoopBackOff"); should {
+		t.Error("a new reason alerted within the cooldown")
+	}
+	clock.Advance(time.Hour)
+	if should, kind, _ := cache.ShouldAlert(ctx, "default/web", "CrashLoopBackOff"); !should || kind != AlertKindReasonChanged {
+		t.Errorf("ShouldAlert() after the cooldown = %v, %q, want true, %q", should, kind, AlertKindReasonChanged)
+	}
+}
+
+func TestMemoryAlertCacheLimitEvictsExpired(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	cache := newMemoryAlertCache(clock, 1)
+
+	if err := cache.Record(ctx, "default/old", "OOMKilled", time.Minute); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	clock.Advance(time.Minute + alertCacheRetention)
+	if res, err := cache.Reserve(ctx, "default/new", "OOMKilled", time.Hour); err != nil || !res.Won {
+		t.Errorf("Reserve() once the only entry expired = %+v, %v, want a win", res, err)
+	}
+}
+
+func TestMemoryAlertCacheRelease(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	cache := newMemoryAlertCache(clock, 0)
+
+	res, _ := cache.Reserve(ctx, "default/web", "OOMKilled", time.Hour)
+	if err := cache.Release(ctx, "default/web", res); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, ok, _ := cache.Get(ctx, "default/web"); ok {
+		t.Error("releasing a first alert left its entry behind")
+	}
+
+	// A release must not undo a newer reservation for the same key
+	stale, _ := cache.Reserve(ctx, "default/web", "OOMKilled", time.Hour)
+	cache.Clear(ctx, "default/web")
+	clock.Advance(time.Second)
+	fresh, _ := cache.Reserve(ctx, "default/web", "OOMKilled", time.Hour)
+	cache.Release(ctx, "default/web", stale)
+	if entry, ok, _ := cache.Get(ctx, "default/web"); !ok || !sameEntry(entry, fresh.Entry) {
+		t.Errorf("a stale release replaced the newer entry: got %+v, %v", entry, ok)
+	}
+}
@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"log"
+	"strings"
+)
+
+// apiCapabilities records which optional APIs the cluster serves.
+// Stripped-down clusters may lack them, and enrichment then skips them
+// instead of failing on every alert.
+type apiCapabilities struct {
+	// events is set when the core v1 events API is served
+	events bool
+	// logs is set when the pods/log subresource is served
+	logs bool
+}
+
+// detectCapabilities asks the discovery API once which optional APIs are available,
+// assuming everything is when discovery itself fails
+func (c *Controller) detectCapabilities() {
+	c.caps = apiCapabilities{events: true, logs: true}
+	if !c.cfg.IncludeEvents && !c.cfg.IncludeLogs && !c.cfg.LivenessEvents && !c.cfg.SandboxEvents && c.cfg.ContainerCreatingTimeout == 0 {
+		return
+	}
+
+	resources, err := c.Clientset.Discovery().ServerResourcesForGroupVersion("v1")
+	if err != nil {
+		log.Printf("WARNING: API discovery failed, assuming events and pod logs are available: %v", err)
+		return
+	}
+	served := make(map[string]bool, len(resources.APIResources))
+	for _, r := range resources.APIResources {
+		served[r.Name] = true
+	}
+	c.caps = apiCapabilities{events: served["events"], logs: served["pods/log"]}
+
+	var missing []string
+	if !c.caps.events {
+		missing = append(missing, "events (INCLUDE_EVENTS, LIVENESS_EVENTS, SANDBOX_EVENTS and volume failure reasons are disabled)")
+	}
+	if !c.caps.logs {
+		missing = append(missing, "pods/log (INCLUDE_LOGS is disabled)")
+	}
+	if len(missing) > 0 {
+		log.Printf("WARNING: Capability degraded: the cluster does not serve %s; alerts are sent without them", strings.Join(missing, " or "))
+	}
+}
@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodConditionRule reports a pod whose condition has held an undesired status for too long
+type PodConditionRule struct {
+	// Type is the condition type, e.g. a readiness gate set by a service mesh
+	Type corev1.PodConditionType
+
+	// Status is the undesired status
+	Status corev1.ConditionStatus
+
+	// For is how long the condition must hold the status before the pod is bad
+	For time.Duration
+
+	spec string
+}
+
+// String describes the rule for logs
+func (r PodConditionRule) String() string {
+	return r.spec
+}
+
+// ParsePodConditionRule parses "type=status[:duration]", e.g. "ContainersReady=False:10m"
+func ParsePodConditionRule(spec string) (PodConditionRule, error) {
+	r := PodConditionRule{spec: spec}
+	condType, rest, ok := strings.Cut(strings.TrimSpace(spec), "=")
+	if !ok || strings.TrimSpace(condType) == "" {
+		return r, fmt.Errorf("expected type=status[:duration]")
+	}
+	r.Type = corev1.PodConditionType(strings.TrimSpace(condType))
+
+	status, after, hasFor := strings.Cut(rest, ":")
+	switch s := corev1.ConditionStatus(strings.TrimSpace(status)); s {
+	case corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionUnknown:
+		r.Status = s
+	default:
+		return r, fmt.Errorf("invalid status %q: expected True, False or Unknown", status)
+	}
+
+	if hasFor {
+		d, err := time.ParseDuration(strings.TrimSpace(after))
+		if err != nil {
+			return r, fmt.Errorf("invalid duration %q: %w", after, err)
+		}
+		if d < 0 {
+			return r, fmt.Errorf("duration must not be negative, got %v", d)
+		}
+		r.For = d
+	}
+	return r, nil
+}
+
+// checkPodConditions returns the first POD_CONDITIONS rule the pod has violated for long enough.
+// The reason is the condition type.
+func (c *Controller) checkPodConditions(pod *corev1.Pod) (bool, badState) {
+	for _, rule := range c.cfg.PodConditions {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type != rule.Type || cond.Status != rule.Status {
+				continue
+			}
+			since := cond.LastTransitionTime.Time
+			if c.clock.Since(since) < rule.For {
+				continue
+			}
+			detail := cond.Message
+			if detail == "" {
+				detail = cond.Reason
+			}
+			return true, badState{
+				Reason: string(cond.Type),
+				Detail: truncate(detail, badStateDetailLimit),
+				Since:  since,
+			}
+		}
+	}
+	return false, badState{}
+}
@@ -0,0 +1,1096 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the monitor's runtime settings.
+// Every field can be set through an environment variable; LoadConfig
+// fills in defaults for anything that is left unset.
+type Config struct {
+	// KubeconfigSecret, if set, reads the kubeconfig from a Secret (KUBECONFIG_SECRET as "namespace/name", KUBECONFIG_SECRET_KEY)
+	KubeconfigSecret *KubeconfigSecret
+
+	// Environment is stamped on every alert and metric, e.g. dev, staging or prod (ENVIRONMENT)
+	Environment string
+
+	// StaticLabels are stamped on every alert, message attribute set and metric (STATIC_LABELS, e.g. "region=eu-west-1,team=platform")
+	StaticLabels map[string]string
+
+	// ClusterName names the cluster in message templates (CLUSTER_NAME)
+	ClusterName string
+
+	// MessagePrefix and MessageSuffix wrap human-readable alert text such as the SNS subject;
+	// {cluster} and {environment} are expanded (MESSAGE_PREFIX, MESSAGE_SUFFIX)
+	MessagePrefix string
+	MessageSuffix string
+
+	// ClientInitRetries is how many times creating the Kubernetes client is retried at startup (CLIENT_INIT_RETRIES)
+	ClientInitRetries int
+
+	// ClientInitBackoff is the wait before the first client creation retry, doubled on each further retry (CLIENT_INIT_BACKOFF)
+	ClientInitBackoff time.Duration
+
+	// WatchNamespaces limits the monitor to these namespaces, with one informer each; empty watches all (WATCH_NAMESPACES)
+	WatchNamespaces []string
+
+	// AgentURL is the base URL of the Python service agent (AGENT_URL)
+	AgentURL string `redact:"url"`
+
+	// AgentURLs, if set, replaces AgentURL with several redundant agents (AGENT_URLS)
+	AgentURLs []string `redact:"url"`
+
+	// AgentPath is the request path appended to each agent URL, a template over the alert (AGENT_PATH, e.g. "/summarize/{{.Namespace}}/{{.Reason}}")
+	AgentPath string
+
+	// AgentGRPCAddr, if set, sends alerts to the agent's SummarizePod RPC at this host:port instead of over HTTP (AGENT_GRPC_ADDR)
+	AgentGRPCAddr string
+
+	// AgentGRPCTLS connects to AgentGRPCAddr over TLS (AGENT_GRPC_TLS)
+	AgentGRPCTLS bool
+
+	// AgentGRPCCAFile is a PEM bundle used instead of the system roots to verify the agent (AGENT_GRPC_CA_FILE)
+	AgentGRPCCAFile string
+
+	// AgentSuccessPolicy decides when an alert sent to AgentURLs counts as delivered: all, any or quorum (AGENT_SUCCESS_POLICY)
+	AgentSuccessPolicy string
+
+	// AgentToken is a bearer token sent to the agent (AGENT_TOKEN)
+	AgentToken string `redact:"secret"`
+
+	// AgentHeaders are extra headers sent on every agent request; $VAR references in values are expanded (AGENT_HEADERS, e.g. "X-Api-Key=$GATEWAY_KEY,X-Tenant=acme")
+	AgentHeaders map[string]string `redact:"secret"`
+
+	// AgentStatusActions map agent HTTP response statuses to success, retry or drop (AGENT_STATUS_ACTIONS, e.g. "409=success,422=drop")
+	AgentStatusActions map[int]AgentAction
+
+	// AgentIdempotencyHeader, when set, carries a hash identifying the alert occurrence on every agent request so the agent can drop duplicates (AGENT_IDEMPOTENCY_HEADER, e.g. "Idempotency-Key")
+	AgentIdempotencyHeader string
+
+	// AgentFieldMap renames top-level fields of the agent payload (AGENT_FIELD_MAP, e.g. "pod_name=pod,namespace=ns,reason=cause")
+	AgentFieldMap map[string]string
+
+	// AgentTokenFile is a file holding the bearer token, re-read on every request so rotation is picked up (AGENT_TOKEN_FILE)
+	AgentTokenFile string
+
+	// MaxConcurrentAgentCalls caps the number of requests in flight to the agent (MAX_CONCURRENT_AGENT_CALLS)
+	MaxConcurrentAgentCalls int
+
+	// AgentMaxQueueAge drops alerts still waiting for an agent slot this long after they were sent, across retries; 0 waits indefinitely (AGENT_MAX_QUEUE_AGE)
+	AgentMaxQueueAge time.Duration
+
+	// AgentTimeout bounds each request to the agent (AGENT_TIMEOUT)
+	AgentTimeout time.Duration
+
+	// AgentMaxRetries is the number of times a failed agent request is retried (AGENT_MAX_RETRIES)
+	AgentMaxRetries int
+
+	// AgentRetryBackoff is the wait before the first retry, doubled on each further retry (AGENT_RETRY_BACKOFF)
+	AgentRetryBackoff time.Duration
+
+	// AgentRetryMaxBackoff caps the doubled retry backoff; 0 leaves it uncapped (AGENT_RETRY_MAX_BACKOFF)
+	AgentRetryMaxBackoff time.Duration
+
+	// AgentRetryDeadline bounds the total time spent delivering one alert, retries included; 0 disables (AGENT_RETRY_DEADLINE)
+	AgentRetryDeadline time.Duration
+
+	// CaptureAgentResponse validates and records the agent's response to each alert (CAPTURE_AGENT_RESPONSE)
+	CaptureAgentResponse bool
+
+	// AgentMaxSuppressFor caps the suppress_for the agent may return for a pod; 0 ignores it (AGENT_MAX_SUPPRESS_FOR)
+	AgentMaxSuppressFor time.Duration
+
+	// AgentSampleRate is the fraction of agent request/response pairs written to the sample sink (AGENT_SAMPLE_RATE)
+	AgentSampleRate float64
+
+	// AgentSampleSink is where sampled pairs are written: stdout or a file path (AGENT_SAMPLE_SINK)
+	AgentSampleSink string
+
+	// NATSURL enables the NATS notifier (NATS_URL)
+	NATSURL string `redact:"url"`
+
+	// NATSSubjectPrefix is the subject prefix alerts are published under (NATS_SUBJECT_PREFIX)
+	NATSSubjectPrefix string
+
+	// SNSTopicARN enables the AWS SNS notifier (SNS_TOPIC_ARN)
+	SNSTopicARN string
+
+	// PubSubProject and PubSubTopic enable the GCP Pub/Sub notifier (PUBSUB_PROJECT, PUBSUB_TOPIC)
+	PubSubProject string
+	PubSubTopic   string
+
+	// SyslogAddr enables the syslog notifier, as host:port of an RFC 5424 server over TCP (SYSLOG_ADDR)
+	SyslogAddr string
+
+	// SyslogTLS connects to the syslog server over TLS (SYSLOG_TLS)
+	SyslogTLS bool
+
+	// SyslogCAFile is a PEM bundle used instead of the system roots to verify the syslog server (SYSLOG_CA_FILE)
+	SyslogCAFile string
+
+	// SyslogMaxRetries is how often a failed syslog send is retried on a new connection (SYSLOG_MAX_RETRIES)
+	SyslogMaxRetries int
+
+	// MetricsAddr is the listen address of the /metrics and /alerts endpoints (METRICS_ADDR)
+	MetricsAddr string
+
+	// HealthAddr is the listen address of /healthz and /readyz; empty serves them on MetricsAddr (HEALTH_ADDR)
+	HealthAddr string
+
+	// ExposeConfig serves the effective configuration, secrets redacted, on /config (EXPOSE_CONFIG)
+	ExposeConfig bool
+
+	// AdminToken enables POST /alerts/clear for requests bearing this token (ADMIN_TOKEN)
+	AdminToken string `redact:"secret"`
+
+	// MetricsBindFatal exits if MetricsAddr can't be bound, instead of running without the server; a separate HealthAddr is always fatal (METRICS_BIND_FATAL)
+	MetricsBindFatal bool
+
+	// ContainerCreatingTimeout is how long a container may sit in ContainerCreating before it is flagged (CONTAINER_CREATING_TIMEOUT)
+	ContainerCreatingTimeout time.Duration
+
+	// CrashLoopWarnRestarts sends a CrashLoopBackOffWarning (info by default) for crash loops with at least
+	// this many restarts that are still below CrashLoopAlertRestarts; 0 disables it (CRASHLOOP_WARN_RESTARTS)
+	CrashLoopWarnRestarts int
+
+	// CrashLoopAlertRestarts is the restart count a crash loop needs before it alerts as CrashLoopBackOff (CRASHLOOP_ALERT_RESTARTS)
+	CrashLoopAlertRestarts int
+
+	// WaitingTimeout flags a container waiting this long for any reason not covered by a specific check; 0 disables (WAITING_TIMEOUT)
+	WaitingTimeout time.Duration
+
+	// AlertCooldown is the default time to wait before re-alerting for the same pod (ALERT_COOLDOWN)
+	AlertCooldown time.Duration
+
+	// RedisURL, if set, shares the alert cache between replicas through Redis (REDIS_URL)
+	RedisURL string `redact:"url"`
+
+	// RedisKeyPrefix namespaces the alert cache keys in Redis (REDIS_KEY_PREFIX)
+	RedisKeyPrefix string
+
+	// NamespaceCooldowns overrides AlertCooldown per namespace (NAMESPACE_COOLDOWNS, e.g. "payments=30m,sandbox=12h")
+	NamespaceCooldowns map[string]time.Duration
+
+	// ReasonCooldowns overrides AlertCooldown and NamespaceCooldowns per failure reason (REASON_COOLDOWNS, e.g. "ImagePullBackOff=12h,OOMKilled=30m")
+	ReasonCooldowns map[string]time.Duration
+
+	// DedupScope is what alerts are deduplicated on: pod, or owner to share one cooldown per workload and reason (DEDUP_SCOPE)
+	DedupScope string
+
+	// NamespaceDedupScopes overrides DedupScope per namespace (NAMESPACE_DEDUP_SCOPES, e.g. "batch=owner")
+	NamespaceDedupScopes map[string]string
+
+	// IgnoreTerminatingPods skips alerts for pods that are being deleted (IGNORE_TERMINATING_PODS)
+	IgnoreTerminatingPods bool
+
+	// MinPodAge is the minimum pod lifetime before its failures are alerted on (MIN_POD_AGE)
+	MinPodAge time.Duration
+
+	// ReasonDebounce is how long a pod must stay bad before a reason is alerted on (REASON_DEBOUNCE, e.g. "ImagePullBackOff=5m")
+	ReasonDebounce map[string]time.Duration
+
+	// DefaultSeverity applies to reasons missing from ReasonSeverities (DEFAULT_SEVERITY)
+	DefaultSeverity Severity
+
+	// ReasonSeverities maps failure reasons to severities (SEVERITY_MAP, e.g. "CrashLoopBackOff=critical,PodFailed=info")
+	ReasonSeverities map[string]Severity
+
+	// CriticalNamespaces are namespaces (or globs) whose alerts are raised to at least CriticalNamespaceSeverity (CRITICAL_NAMESPACES)
+	CriticalNamespaces []string
+
+	// CriticalNamespaceSeverity is the severity floor in CriticalNamespaces (CRITICAL_NAMESPACE_SEVERITY)
+	CriticalNamespaceSeverity Severity
+
+	// MaintenanceWindows are weekly windows during which alerts are logged but not sent (MAINTENANCE_WINDOWS, e.g. "Sat 00:00-06:00,payments@Mon-Fri 22:00-23:30")
+	MaintenanceWindows []MaintenanceWindow
+
+	// MaintenanceLocation is the time zone of MaintenanceWindows (MAINTENANCE_TIMEZONE)
+	MaintenanceLocation *time.Location
+
+	// ImagePullMinFailures is the number of failed image pull attempts before a container alerts (IMAGE_PULL_MIN_FAILURES)
+	ImagePullMinFailures int
+
+	// BadPhases are the pod phases that count as bad on their own (BAD_PHASES, e.g. "Failed,Unknown")
+	BadPhases []string
+
+	// PodConditions are pod conditions that make a pod bad once they hold a status long enough (POD_CONDITIONS, e.g. "mesh.example.com/ready=False:5m")
+	PodConditions []PodConditionRule
+
+	// ChronicFailureAfter is how long a pod must stay bad to count as a chronic failure (CHRONIC_FAILURE_AFTER)
+	ChronicFailureAfter time.Duration
+
+	// EscalateChronic sends one alert of kind chronic when a pod becomes a chronic failure (ESCALATE_CHRONIC)
+	EscalateChronic bool
+
+	// NotifyResolved sends a resolved alert when an alerted pod recovers (NOTIFY_RESOLVED)
+	NotifyResolved bool
+
+	// ResolvedMinInterval is the least time between two resolved alerts for the same pod; 0 sends every one (RESOLVED_MIN_INTERVAL)
+	ResolvedMinInterval time.Duration
+
+	// NotifierMinSeverities is the lowest severity each notifier receives, by notifier name (NOTIFIER_MIN_SEVERITY, e.g. "agent=warning,sns=critical")
+	NotifierMinSeverities map[string]Severity
+
+	// AuditSink, if set, is where a JSON line is appended for every alerting decision: stdout or a file path (AUDIT_SINK)
+	AuditSink string
+
+	// NotifierTimeouts bound each notifier's handling of one alert, by notifier name, over the per-type defaults; 0 is unbounded (NOTIFIER_TIMEOUTS, e.g. "sns=5s,syslog=3s")
+	NotifierTimeouts map[string]time.Duration
+
+	// NotifierRoutes send the alerts of pods matching a label selector to only some notifiers (NOTIFIER_ROUTES, e.g. "oncall-team=storage:agent|sns")
+	NotifierRoutes []NotifierRoute
+
+	// LivenessEvents alerts on repeated liveness probe failure events (LIVENESS_EVENTS)
+	LivenessEvents bool
+
+	// SandboxEvents alerts on pods whose sandbox could not be created, from FailedCreatePodSandBox events (SANDBOX_EVENTS)
+	SandboxEvents bool
+
+	// LivenessFailureThreshold is how many liveness failures within LivenessFailureWindow trigger an alert (LIVENESS_FAILURE_THRESHOLD)
+	LivenessFailureThreshold int
+
+	// LivenessFailureWindow is the window liveness failures are counted over (LIVENESS_FAILURE_WINDOW)
+	LivenessFailureWindow time.Duration
+
+	// NodeCorrelation folds pods failing together on a NotReady node into one alert (NODE_CORRELATION)
+	NodeCorrelation bool
+
+	// NodeCorrelationWindow is how long pod alerts are held to group them by node (NODE_CORRELATION_WINDOW)
+	NodeCorrelationWindow time.Duration
+
+	// NodeCorrelationMinPods is how many pods must fail on a node within the window to fold them (NODE_CORRELATION_MIN_PODS)
+	NodeCorrelationMinPods int
+
+	// CorrelationLabel is a pod label whose pods failing together are folded into one alert, e.g. a config revision (CORRELATION_LABEL)
+	CorrelationLabel string
+
+	// CorrelationWindow is how long pod alerts are held to group them by CorrelationLabel (CORRELATION_WINDOW)
+	CorrelationWindow time.Duration
+
+	// CorrelationMinPods is how many pods sharing a CorrelationLabel value must fail within the window to fold them (CORRELATION_MIN_PODS)
+	CorrelationMinPods int
+
+	// WatchDeployments alerts on Deployments with too few available replicas (WATCH_DEPLOYMENTS)
+	WatchDeployments bool
+
+	// DeploymentAvailableFraction is the fraction of desired replicas that must be available (DEPLOYMENT_AVAILABLE_FRACTION)
+	DeploymentAvailableFraction float64
+
+	// DeploymentUnavailableThreshold is how long a Deployment may stay below the fraction before alerting (DEPLOYMENT_UNAVAILABLE_THRESHOLD)
+	DeploymentUnavailableThreshold time.Duration
+
+	// WatchDaemonSets alerts on DaemonSets with nodes that lack a ready pod (WATCH_DAEMONSETS)
+	WatchDaemonSets bool
+
+	// DaemonSetMaxMissing is how many of a DaemonSet's nodes may lack a ready pod without alerting (DAEMONSET_MAX_MISSING)
+	DaemonSetMaxMissing int
+
+	// DaemonSetDegradedThreshold is how long a DaemonSet may have too many missing pods before alerting (DAEMONSET_DEGRADED_THRESHOLD)
+	DaemonSetDegradedThreshold time.Duration
+
+	// WatchAlertRules applies PodAlertRule custom resources in the watched namespaces (WATCH_ALERT_RULES)
+	WatchAlertRules bool
+
+	// SuppressionConfigMap is a "namespace/name" ConfigMap of suppression rules, reloaded live (SUPPRESSION_CONFIGMAP)
+	SuppressionConfigMap string
+
+	// IgnoreContainers are container names or globs whose bad states never alert (IGNORE_CONTAINERS)
+	IgnoreContainers []string
+
+	// IgnoreTerminatedReasons are terminated container reasons that are never a failure, whatever the exit code (IGNORE_TERMINATED_REASONS)
+	IgnoreTerminatedReasons []string
+
+	// BenignTerminations are patterns matched against a terminated container's reason and message;
+	// a match is a known-harmless exit, not a failure (BENIGN_TERMINATIONS, semicolon-separated)
+	BenignTerminations []*regexp.Regexp
+
+	// IdentityLabels are pod label keys (or globs) copied into each alert (IDENTITY_LABELS; set it empty to copy none)
+	IdentityLabels []string
+
+	// IncludeResources attaches the failing container's requests/limits: always, memory (only after an OOM kill) or never (INCLUDE_RESOURCES)
+	IncludeResources string
+
+	// IncludeImage attaches the image of the failing container from the pod spec (INCLUDE_IMAGE)
+	IncludeImage bool
+
+	// IncludeEvents attaches the pod's events to each alert (INCLUDE_EVENTS)
+	IncludeEvents bool
+
+	// IncludeLogs attaches the tail of each container's log to each alert (INCLUDE_LOGS)
+	IncludeLogs bool
+
+	// LogTailLines is the number of log lines attached per container (LOG_TAIL_LINES)
+	LogTailLines int
+
+	// LogRetryAttempts is how often a log that isn't available yet is fetched again; 0 disables (LOG_RETRY_ATTEMPTS)
+	LogRetryAttempts int
+
+	// LogRetryDelay is the wait between those attempts (LOG_RETRY_DELAY)
+	LogRetryDelay time.Duration
+
+	// EnrichmentTimeout bounds fetching events and logs for one alert; 0 disables (ENRICHMENT_TIMEOUT)
+	EnrichmentTimeout time.Duration
+
+	// ResyncPeriod is the informer resync period, jittered by up to 10% (RESYNC_PERIOD)
+	ResyncPeriod time.Duration
+
+	// WatchFailureThreshold is the number of informer watch failures within WatchFailureWindow after which the process exits; 0 disables (WATCH_FAILURE_THRESHOLD)
+	WatchFailureThreshold int
+
+	// WatchFailureWindow is the sliding window for WatchFailureThreshold (WATCH_FAILURE_WINDOW)
+	WatchFailureWindow time.Duration
+
+	// StartupGrace delays alerting on newly added pods after the initial sync (STARTUP_GRACE)
+	StartupGrace time.Duration
+
+	// ShutdownTimeout is how long shutdown may take before the process exits regardless (SHUTDOWN_TIMEOUT)
+	ShutdownTimeout time.Duration
+
+	// MaxPayloadBytes caps the JSON size of an alert by truncating logs, events and detail in that order; 0 disables (MAX_PAYLOAD_BYTES)
+	MaxPayloadBytes int
+
+	// RecheckInterval is how often pods in the informer store are re-evaluated for time-based failures (RECHECK_INTERVAL)
+	RecheckInterval time.Duration
+
+	// HeartbeatInterval is how often the monitor logs a summary of its state; 0 disables it (HEARTBEAT_INTERVAL)
+	HeartbeatInterval time.Duration
+
+	// InventoryFile, if set, is where a JSON snapshot of the failing pods is written (INVENTORY_FILE)
+	InventoryFile string
+
+	// InventoryInterval is how often INVENTORY_FILE is rewritten; it is also written on shutdown (INVENTORY_INTERVAL)
+	InventoryInterval time.Duration
+
+	// MaxFailingPods caps how many failing pods are tracked; pods beyond it still alert but get no
+	// chronic escalation, inventory entry or bad-state duration; 0 is unlimited (MAX_FAILING_PODS)
+	MaxFailingPods int
+
+	// MaxAlertCacheEntries caps the in-memory alert cache; alerts for new keys beyond it are suppressed; 0 is unlimited (MAX_ALERT_CACHE_ENTRIES)
+	MaxAlertCacheEntries int
+
+	// SelfTestNamespace is where --selftest creates its failing pod (SELFTEST_NAMESPACE)
+	SelfTestNamespace string
+
+	// SelfTestTimeout is how long --selftest waits for the pod to be detected and alerted on (SELFTEST_TIMEOUT)
+	SelfTestTimeout time.Duration
+}
+
+// LoadConfig resolves the configuration from the environment
+func LoadConfig() (*Config, error) {
+	cfg := &Config{
+		ClientInitRetries:       5,
+		ClientInitBackoff:       2 * time.Second,
+		AgentURL:                "http://localhost:8000",
+		AgentPath:               agentSummarizePath,
+		AgentSuccessPolicy:      "any",
+		MaxConcurrentAgentCalls: 5,
+		AgentTimeout:            30 * time.Second,
+		AgentMaxRetries:         2,
+		AgentRetryBackoff:       time.Second,
+		AgentRetryMaxBackoff:    30 * time.Second,
+		AgentMaxSuppressFor:     24 * time.Hour,
+		AgentSampleSink:         "stdout",
+		MetricsAddr:             ":8080",
+		NATSSubjectPrefix:       "k8s.pod.failed",
+		SyslogMaxRetries:        2,
+
+		ContainerCreatingTimeout: 5 * time.Minute,
+		RecheckInterval:          time.Minute,
+		HeartbeatInterval:        5 * time.Minute,
+		InventoryInterval:        5 * time.Minute,
+		SelfTestNamespace:        "default",
+		SelfTestTimeout:          3 * time.Minute,
+		AlertCooldown:            alertWaitPeriod,
+		DedupScope:               DedupScopePod,
+		RedisKeyPrefix:           "watch-my-pod:alert:",
+		ResyncPeriod:             10 * time.Minute,
+		StartupGrace:             30 * time.Second,
+		WatchFailureThreshold:    10,
+		WatchFailureWindow:       5 * time.Minute,
+		ShutdownTimeout:          30 * time.Second,
+
+		LogTailLines:         50,
+		LogRetryAttempts:     2,
+		LogRetryDelay:        time.Second,
+		EnrichmentTimeout:    10 * time.Second,
+		MaxPayloadBytes:      64 * 1024,
+		IdentityLabels:       []string{"app.kubernetes.io/*"},
+		BadPhases:            []string{"Failed"},
+		ImagePullMinFailures: 1,
+		IncludeResources:     includeResourcesMemory,
+		IncludeImage:         true,
+
+		ChronicFailureAfter:            24 * time.Hour,
+		LivenessFailureThreshold:       3,
+		LivenessFailureWindow:          10 * time.Minute,
+		NodeCorrelationWindow:          time.Minute,
+		NodeCorrelationMinPods:         5,
+		CorrelationWindow:              time.Minute,
+		CorrelationMinPods:             2,
+		DeploymentAvailableFraction:    1,
+		DeploymentUnavailableThreshold: 10 * time.Minute,
+		DaemonSetDegradedThreshold:     10 * time.Minute,
+
+		DefaultSeverity:           SeverityWarning,
+		IgnoreTerminatingPods:     true,
+		CriticalNamespaceSeverity: SeverityWarning,
+	}
+
+	var err error
+	if ref := os.Getenv("KUBECONFIG_SECRET"); ref != "" {
+		ns, name, ok := strings.Cut(ref, "/")
+		if !ok || ns == "" || name == "" {
+			return nil, fmt.Errorf("invalid KUBECONFIG_SECRET %q: expected namespace/name", ref)
+		}
+		cfg.KubeconfigSecret = &KubeconfigSecret{
+			Namespace: ns,
+			Name:      name,
+			Key:       envString("KUBECONFIG_SECRET_KEY", "kubeconfig"),
+		}
+	}
+	if cfg.ClientInitRetries, err = envInt("CLIENT_INIT_RETRIES", cfg.ClientInitRetries); err != nil {
+		return nil, err
+	}
+	if cfg.ClientInitRetries < 0 {
+		return nil, fmt.Errorf("CLIENT_INIT_RETRIES must not be negative, got %d", cfg.ClientInitRetries)
+	}
+	if cfg.ClientInitBackoff, err = envDuration("CLIENT_INIT_BACKOFF", cfg.ClientInitBackoff); err != nil {
+		return nil, err
+	}
+	if cfg.ClientInitBackoff < 0 {
+		return nil, fmt.Errorf("CLIENT_INIT_BACKOFF must not be negative, got %v", cfg.ClientInitBackoff)
+	}
+	cfg.WatchNamespaces = envList("WATCH_NAMESPACES")
+	cfg.AgentURL = envString("AGENT_URL", cfg.AgentURL)
+	cfg.AgentURLs = envList("AGENT_URLS")
+	cfg.AgentPath = envString("AGENT_PATH", cfg.AgentPath)
+	cfg.AgentGRPCAddr = envString("AGENT_GRPC_ADDR", cfg.AgentGRPCAddr)
+	if cfg.AgentGRPCTLS, err = envBool("AGENT_GRPC_TLS", cfg.AgentGRPCTLS); err != nil {
+		return nil, err
+	}
+	cfg.AgentGRPCCAFile = envString("AGENT_GRPC_CA_FILE", cfg.AgentGRPCCAFile)
+	if cfg.AgentGRPCCAFile != "" && !cfg.AgentGRPCTLS {
+		return nil, fmt.Errorf("AGENT_GRPC_CA_FILE requires AGENT_GRPC_TLS")
+	}
+	if cfg.AgentGRPCAddr != "" && len(cfg.AgentURLs) > 0 {
+		return nil, fmt.Errorf("AGENT_GRPC_ADDR and AGENT_URLS are mutually exclusive")
+	}
+	cfg.AgentSuccessPolicy = envString("AGENT_SUCCESS_POLICY", cfg.AgentSuccessPolicy)
+	cfg.AgentToken = envString("AGENT_TOKEN", cfg.AgentToken)
+	cfg.AgentTokenFile = envString("AGENT_TOKEN_FILE", cfg.AgentTokenFile)
+	if cfg.AgentStatusActions, err = parseAgentStatusActions("AGENT_STATUS_ACTIONS"); err != nil {
+		return nil, err
+	}
+	if cfg.AgentHeaders, err = envStringMap("AGENT_HEADERS"); err != nil {
+		return nil, err
+	}
+	for name, value := range cfg.AgentHeaders {
+		cfg.AgentHeaders[name] = os.ExpandEnv(value)
+	}
+	cfg.AgentIdempotencyHeader = envString("AGENT_IDEMPOTENCY_HEADER", cfg.AgentIdempotencyHeader)
+	if cfg.AgentFieldMap, err = envStringMap("AGENT_FIELD_MAP"); err != nil {
+		return nil, err
+	}
+	if err := validateFieldMap(cfg.AgentFieldMap); err != nil {
+		return nil, fmt.Errorf("invalid AGENT_FIELD_MAP: %w", err)
+	}
+	if cfg.AgentToken != "" && cfg.AgentTokenFile != "" {
+		return nil, fmt.Errorf("AGENT_TOKEN and AGENT_TOKEN_FILE are mutually exclusive")
+	}
+	if cfg.MaxConcurrentAgentCalls, err = envInt("MAX_CONCURRENT_AGENT_CALLS", cfg.MaxConcurrentAgentCalls); err != nil {
+		return nil, err
+	}
+	if cfg.MaxConcurrentAgentCalls < 1 {
+		return nil, fmt.Errorf("MAX_CONCURRENT_AGENT_CALLS must be at least 1, got %d", cfg.MaxConcurrentAgentCalls)
+	}
+	if cfg.AgentMaxQueueAge, err = envDuration("AGENT_MAX_QUEUE_AGE", cfg.AgentMaxQueueAge); err != nil {
+		return nil, err
+	}
+	if cfg.AgentMaxQueueAge < 0 {
+		return nil, fmt.Errorf("AGENT_MAX_QUEUE_AGE must not be negative, got %v", cfg.AgentMaxQueueAge)
+	}
+	if cfg.AgentTimeout, err = envDuration("AGENT_TIMEOUT", cfg.AgentTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.AgentTimeout <= 0 {
+		return nil, fmt.Errorf("AGENT_TIMEOUT must be positive, got %v", cfg.AgentTimeout)
+	}
+	if cfg.AgentMaxRetries, err = envInt("AGENT_MAX_RETRIES", cfg.AgentMaxRetries); err != nil {
+		return nil, err
+	}
+	if cfg.AgentMaxRetries < 0 {
+		return nil, fmt.Errorf("AGENT_MAX_RETRIES must not be negative, got %d", cfg.AgentMaxRetries)
+	}
+	if cfg.AgentRetryBackoff, err = envDuration("AGENT_RETRY_BACKOFF", cfg.AgentRetryBackoff); err != nil {
+		return nil, err
+	}
+	if cfg.AgentRetryBackoff < 0 {
+		return nil, fmt.Errorf("AGENT_RETRY_BACKOFF must not be negative, got %v", cfg.AgentRetryBackoff)
+	}
+	if cfg.AgentRetryMaxBackoff, err = envDuration("AGENT_RETRY_MAX_BACKOFF", cfg.AgentRetryMaxBackoff); err != nil {
+		return nil, err
+	}
+	if cfg.AgentRetryDeadline, err = envDuration("AGENT_RETRY_DEADLINE", cfg.AgentRetryDeadline); err != nil {
+		return nil, err
+	}
+	if cfg.CaptureAgentResponse, err = envBool("CAPTURE_AGENT_RESPONSE", cfg.CaptureAgentResponse); err != nil {
+		return nil, err
+	}
+	if cfg.AgentMaxSuppressFor, err = envDuration("AGENT_MAX_SUPPRESS_FOR", cfg.AgentMaxSuppressFor); err != nil {
+		return nil, err
+	}
+	if cfg.AgentMaxSuppressFor < 0 {
+		return nil, fmt.Errorf("AGENT_MAX_SUPPRESS_FOR must not be negative, got %v", cfg.AgentMaxSuppressFor)
+	}
+	if cfg.AgentSampleRate, err = envFloat("AGENT_SAMPLE_RATE", cfg.AgentSampleRate); err != nil {
+		return nil, err
+	}
+	if r := cfg.AgentSampleRate; r < 0 || r > 1 {
+		return nil, fmt.Errorf("AGENT_SAMPLE_RATE must be in [0, 1], got %v", r)
+	}
+	cfg.AgentSampleSink = envString("AGENT_SAMPLE_SINK", cfg.AgentSampleSink)
+	if cfg.AgentSampleSink == "" {
+		return nil, fmt.Errorf("AGENT_SAMPLE_SINK must not be empty")
+	}
+	cfg.SNSTopicARN = envString("SNS_TOPIC_ARN", cfg.SNSTopicARN)
+	cfg.PubSubProject = envString("PUBSUB_PROJECT", cfg.PubSubProject)
+	cfg.PubSubTopic = envString("PUBSUB_TOPIC", cfg.PubSubTopic)
+	if cfg.MetricsBindFatal, err = envBool("METRICS_BIND_FATAL", cfg.MetricsBindFatal); err != nil {
+		return nil, err
+	}
+	cfg.NATSURL = envString("NATS_URL", cfg.NATSURL)
+	cfg.NATSSubjectPrefix = envString("NATS_SUBJECT_PREFIX", cfg.NATSSubjectPrefix)
+	cfg.SyslogAddr = envString("SYSLOG_ADDR", cfg.SyslogAddr)
+	if cfg.SyslogTLS, err = envBool("SYSLOG_TLS", cfg.SyslogTLS); err != nil {
+		return nil, err
+	}
+	cfg.SyslogCAFile = envString("SYSLOG_CA_FILE", cfg.SyslogCAFile)
+	if cfg.SyslogCAFile != "" && !cfg.SyslogTLS {
+		return nil, fmt.Errorf("SYSLOG_CA_FILE requires SYSLOG_TLS")
+	}
+	if cfg.SyslogMaxRetries, err = envInt("SYSLOG_MAX_RETRIES", cfg.SyslogMaxRetries); err != nil {
+		return nil, err
+	}
+	if cfg.SyslogMaxRetries < 0 {
+		return nil, fmt.Errorf("SYSLOG_MAX_RETRIES must not be negative, got %d", cfg.SyslogMaxRetries)
+	}
+	cfg.MetricsAddr = envString("METRICS_ADDR", cfg.MetricsAddr)
+	cfg.HealthAddr = envString("HEALTH_ADDR", cfg.HealthAddr)
+	if cfg.ExposeConfig, err = envBool("EXPOSE_CONFIG", cfg.ExposeConfig); err != nil {
+		return nil, err
+	}
+	cfg.AdminToken = envString("ADMIN_TOKEN", cfg.AdminToken)
+	cfg.Environment = envString("ENVIRONMENT", cfg.Environment)
+	if cfg.StaticLabels, err = envStringMap("STATIC_LABELS"); err != nil {
+		return nil, err
+	}
+	if err := validateStaticLabels(cfg.StaticLabels); err != nil {
+		return nil, fmt.Errorf("invalid STATIC_LABELS: %w", err)
+	}
+	cfg.ClusterName = envString("CLUSTER_NAME", cfg.ClusterName)
+	cfg.MessagePrefix = envString("MESSAGE_PREFIX", cfg.MessagePrefix)
+	if err := validateMessageTemplate("MESSAGE_PREFIX", cfg.MessagePrefix); err != nil {
+		return nil, err
+	}
+	cfg.MessageSuffix = envString("MESSAGE_SUFFIX", cfg.MessageSuffix)
+	if err := validateMessageTemplate("MESSAGE_SUFFIX", cfg.MessageSuffix); err != nil {
+		return nil, err
+	}
+	if cfg.ContainerCreatingTimeout, err = envDuration("CONTAINER_CREATING_TIMEOUT", cfg.ContainerCreatingTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.WaitingTimeout, err = envDuration("WAITING_TIMEOUT", cfg.WaitingTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.CrashLoopWarnRestarts, err = envInt("CRASHLOOP_WARN_RESTARTS", cfg.CrashLoopWarnRestarts); err != nil {
+		return nil, err
+	}
+	if cfg.CrashLoopWarnRestarts < 0 {
+		return nil, fmt.Errorf("CRASHLOOP_WARN_RESTARTS must not be negative, got %d", cfg.CrashLoopWarnRestarts)
+	}
+	if cfg.CrashLoopAlertRestarts, err = envInt("CRASHLOOP_ALERT_RESTARTS", cfg.CrashLoopAlertRestarts); err != nil {
+		return nil, err
+	}
+	if cfg.CrashLoopAlertRestarts < 0 {
+		return nil, fmt.Errorf("CRASHLOOP_ALERT_RESTARTS must not be negative, got %d", cfg.CrashLoopAlertRestarts)
+	}
+	if cfg.CrashLoopWarnRestarts > 0 && cfg.CrashLoopWarnRestarts >= cfg.CrashLoopAlertRestarts {
+		return nil, fmt.Errorf("CRASHLOOP_WARN_RESTARTS must be below CRASHLOOP_ALERT_RESTARTS (%d), got %d", cfg.CrashLoopAlertRestarts, cfg.CrashLoopWarnRestarts)
+	}
+	if cfg.AlertCooldown, err = envDuration("ALERT_COOLDOWN", cfg.AlertCooldown); err != nil {
+		return nil, err
+	}
+	if cfg.AlertCooldown < 0 {
+		return nil, fmt.Errorf("ALERT_COOLDOWN must not be negative, got %v", cfg.AlertCooldown)
+	}
+	cfg.RedisURL = envString("REDIS_URL", cfg.RedisURL)
+	cfg.RedisKeyPrefix = envString("REDIS_KEY_PREFIX", cfg.RedisKeyPrefix)
+	if cfg.ReasonCooldowns, err = envDurationMap("REASON_COOLDOWNS"); err != nil {
+		return nil, err
+	}
+	if cfg.NamespaceCooldowns, err = envDurationMap("NAMESPACE_COOLDOWNS"); err != nil {
+		return nil, err
+	}
+	cfg.DedupScope = envString("DEDUP_SCOPE", cfg.DedupScope)
+	if !validDedupScope(cfg.DedupScope) {
+		return nil, fmt.Errorf("DEDUP_SCOPE must be %q or %q, got %q", DedupScopePod, DedupScopeOwner, cfg.DedupScope)
+	}
+	if cfg.NamespaceDedupScopes, err = envStringMap("NAMESPACE_DEDUP_SCOPES"); err != nil {
+		return nil, err
+	}
+	for ns, scope := range cfg.NamespaceDedupScopes {
+		if !validDedupScope(scope) {
+			return nil, fmt.Errorf("NAMESPACE_DEDUP_SCOPES must map namespaces to %q or %q, got %q for %s", DedupScopePod, DedupScopeOwner, scope, ns)
+		}
+	}
+	if cfg.IgnoreTerminatingPods, err = envBool("IGNORE_TERMINATING_PODS", cfg.IgnoreTerminatingPods); err != nil {
+		return nil, err
+	}
+	if cfg.MinPodAge, err = envDuration("MIN_POD_AGE", cfg.MinPodAge); err != nil {
+		return nil, err
+	}
+	if cfg.ReasonDebounce, err = envDurationMap("REASON_DEBOUNCE"); err != nil {
+		return nil, err
+	}
+	if cfg.RecheckInterval, err = envDuration("RECHECK_INTERVAL", cfg.RecheckInterval); err != nil {
+		return nil, err
+	}
+	if cfg.RecheckInterval <= 0 {
+		return nil, fmt.Errorf("RECHECK_INTERVAL must be positive, got %v", cfg.RecheckInterval)
+	}
+	if cfg.HeartbeatInterval, err = envDuration("HEARTBEAT_INTERVAL", cfg.HeartbeatInterval); err != nil {
+		return nil, err
+	}
+	if cfg.HeartbeatInterval < 0 {
+		return nil, fmt.Errorf("HEARTBEAT_INTERVAL must not be negative, got %v", cfg.HeartbeatInterval)
+	}
+	cfg.InventoryFile = envString("INVENTORY_FILE", cfg.InventoryFile)
+	if cfg.InventoryInterval, err = envDuration("INVENTORY_INTERVAL", cfg.InventoryInterval); err != nil {
+		return nil, err
+	}
+	if cfg.InventoryFile != "" && cfg.InventoryInterval <= 0 {
+		return nil, fmt.Errorf("INVENTORY_INTERVAL must be positive, got %v", cfg.InventoryInterval)
+	}
+	if cfg.MaxFailingPods, err = envInt("MAX_FAILING_PODS", cfg.MaxFailingPods); err != nil {
+		return nil, err
+	}
+	if cfg.MaxFailingPods < 0 {
+		return nil, fmt.Errorf("MAX_FAILING_PODS must not be negative, got %v", cfg.MaxFailingPods)
+	}
+	if cfg.MaxAlertCacheEntries, err = envInt("MAX_ALERT_CACHE_ENTRIES", cfg.MaxAlertCacheEntries); err != nil {
+		return nil, err
+	}
+	if cfg.MaxAlertCacheEntries < 0 {
+		return nil, fmt.Errorf("MAX_ALERT_CACHE_ENTRIES must not be negative, got %v", cfg.MaxAlertCacheEntries)
+	}
+	cfg.SelfTestNamespace = envString("SELFTEST_NAMESPACE", cfg.SelfTestNamespace)
+	if cfg.SelfTestTimeout, err = envDuration("SELFTEST_TIMEOUT", cfg.SelfTestTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.SelfTestTimeout <= 0 {
+		return nil, fmt.Errorf("SELFTEST_TIMEOUT must be positive, got %v", cfg.SelfTestTimeout)
+	}
+	if cfg.ResyncPeriod, err = envDuration("RESYNC_PERIOD", cfg.ResyncPeriod); err != nil {
+		return nil, err
+	}
+	if cfg.ResyncPeriod < 0 {
+		return nil, fmt.Errorf("RESYNC_PERIOD must not be negative, got %v", cfg.ResyncPeriod)
+	}
+	if cfg.WatchFailureThreshold, err = envInt("WATCH_FAILURE_THRESHOLD", cfg.WatchFailureThreshold); err != nil {
+		return nil, err
+	}
+	if cfg.WatchFailureWindow, err = envDuration("WATCH_FAILURE_WINDOW", cfg.WatchFailureWindow); err != nil {
+		return nil, err
+	}
+	if cfg.WatchFailureThreshold > 0 && cfg.WatchFailureWindow <= 0 {
+		return nil, fmt.Errorf("WATCH_FAILURE_WINDOW must be positive, got %v", cfg.WatchFailureWindow)
+	}
+	if cfg.StartupGrace, err = envDuration("STARTUP_GRACE", cfg.StartupGrace); err != nil {
+		return nil, err
+	}
+	if cfg.StartupGrace < 0 {
+		return nil, fmt.Errorf("STARTUP_GRACE must not be negative, got %v", cfg.StartupGrace)
+	}
+	if cfg.ShutdownTimeout, err = envDuration("SHUTDOWN_TIMEOUT", cfg.ShutdownTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		return nil, fmt.Errorf("SHUTDOWN_TIMEOUT must be positive, got %v", cfg.ShutdownTimeout)
+	}
+	if cfg.DefaultSeverity, err = envSeverity("DEFAULT_SEVERITY", cfg.DefaultSeverity); err != nil {
+		return nil, err
+	}
+	if cfg.ReasonSeverities, err = envSeverityMap("SEVERITY_MAP"); err != nil {
+		return nil, err
+	}
+	cfg.CriticalNamespaces = envList("CRITICAL_NAMESPACES")
+	if cfg.CriticalNamespaceSeverity, err = envSeverity("CRITICAL_NAMESPACE_SEVERITY", cfg.CriticalNamespaceSeverity); err != nil {
+		return nil, err
+	}
+	for _, spec := range envList("MAINTENANCE_WINDOWS") {
+		w, err := ParseMaintenanceWindow(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MAINTENANCE_WINDOWS entry %q: %w", spec, err)
+		}
+		cfg.MaintenanceWindows = append(cfg.MaintenanceWindows, w)
+	}
+	if cfg.MaintenanceLocation, err = time.LoadLocation(envString("MAINTENANCE_TIMEZONE", "UTC")); err != nil {
+		return nil, fmt.Errorf("invalid MAINTENANCE_TIMEZONE: %w", err)
+	}
+	if cfg.ImagePullMinFailures, err = envInt("IMAGE_PULL_MIN_FAILURES", cfg.ImagePullMinFailures); err != nil {
+		return nil, err
+	}
+	if cfg.ImagePullMinFailures < 1 {
+		return nil, fmt.Errorf("IMAGE_PULL_MIN_FAILURES must be at least 1, got %d", cfg.ImagePullMinFailures)
+	}
+	if _, ok := os.LookupEnv("BAD_PHASES"); ok {
+		cfg.BadPhases = envList("BAD_PHASES")
+	}
+	for _, phase := range cfg.BadPhases {
+		if !badPhaseChoices[phase] {
+			return nil, fmt.Errorf("BAD_PHASES must only list Failed or Unknown, got %q", phase)
+		}
+	}
+	for _, spec := range envList("POD_CONDITIONS") {
+		r, err := ParsePodConditionRule(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid POD_CONDITIONS entry %q: %w", spec, err)
+		}
+		cfg.PodConditions = append(cfg.PodConditions, r)
+	}
+	if cfg.ChronicFailureAfter, err = envDuration("CHRONIC_FAILURE_AFTER", cfg.ChronicFailureAfter); err != nil {
+		return nil, err
+	}
+	if cfg.ChronicFailureAfter <= 0 {
+		return nil, fmt.Errorf("CHRONIC_FAILURE_AFTER must be positive, got %v", cfg.ChronicFailureAfter)
+	}
+	if cfg.EscalateChronic, err = envBool("ESCALATE_CHRONIC", cfg.EscalateChronic); err != nil {
+		return nil, err
+	}
+	if cfg.NotifyResolved, err = envBool("NOTIFY_RESOLVED", cfg.NotifyResolved); err != nil {
+		return nil, err
+	}
+	if cfg.ResolvedMinInterval, err = envDuration("RESOLVED_MIN_INTERVAL", cfg.ResolvedMinInterval); err != nil {
+		return nil, err
+	}
+	if cfg.NotifierMinSeverities, err = envSeverityPairs("NOTIFIER_MIN_SEVERITY"); err != nil {
+		return nil, err
+	}
+	if cfg.NotifierTimeouts, err = envDurationMap("NOTIFIER_TIMEOUTS"); err != nil {
+		return nil, err
+	}
+	cfg.AuditSink = envString("AUDIT_SINK", cfg.AuditSink)
+	if cfg.NotifierRoutes, err = ParseNotifierRoutes(os.Getenv("NOTIFIER_ROUTES")); err != nil {
+		return nil, fmt.Errorf("invalid NOTIFIER_ROUTES: %w", err)
+	}
+	if cfg.LivenessEvents, err = envBool("LIVENESS_EVENTS", cfg.LivenessEvents); err != nil {
+		return nil, err
+	}
+	if cfg.SandboxEvents, err = envBool("SANDBOX_EVENTS", cfg.SandboxEvents); err != nil {
+		return nil, err
+	}
+	if cfg.LivenessFailureThreshold, err = envInt("LIVENESS_FAILURE_THRESHOLD", cfg.LivenessFailureThreshold); err != nil {
+		return nil, err
+	}
+	if cfg.LivenessFailureThreshold < 1 {
+		return nil, fmt.Errorf("LIVENESS_FAILURE_THRESHOLD must be at least 1, got %d", cfg.LivenessFailureThreshold)
+	}
+	if cfg.LivenessFailureWindow, err = envDuration("LIVENESS_FAILURE_WINDOW", cfg.LivenessFailureWindow); err != nil {
+		return nil, err
+	}
+	if cfg.LivenessFailureWindow <= 0 {
+		return nil, fmt.Errorf("LIVENESS_FAILURE_WINDOW must be positive, got %v", cfg.LivenessFailureWindow)
+	}
+	if cfg.NodeCorrelation, err = envBool("NODE_CORRELATION", cfg.NodeCorrelation); err != nil {
+		return nil, err
+	}
+	if cfg.NodeCorrelationWindow, err = envDuration("NODE_CORRELATION_WINDOW", cfg.NodeCorrelationWindow); err != nil {
+		return nil, err
+	}
+	if cfg.NodeCorrelationWindow <= 0 {
+		return nil, fmt.Errorf("NODE_CORRELATION_WINDOW must be positive, got %v", cfg.NodeCorrelationWindow)
+	}
+	if cfg.NodeCorrelationMinPods, err = envInt("NODE_CORRELATION_MIN_PODS", cfg.NodeCorrelationMinPods); err != nil {
+		return nil, err
+	}
+	if cfg.NodeCorrelationMinPods < 2 {
+		return nil, fmt.Errorf("NODE_CORRELATION_MIN_PODS must be at least 2, got %d", cfg.NodeCorrelationMinPods)
+	}
+	cfg.CorrelationLabel = envString("CORRELATION_LABEL", cfg.CorrelationLabel)
+	if cfg.CorrelationWindow, err = envDuration("CORRELATION_WINDOW", cfg.CorrelationWindow); err != nil {
+		return nil, err
+	}
+	if cfg.CorrelationWindow <= 0 {
+		return nil, fmt.Errorf("CORRELATION_WINDOW must be positive, got %v", cfg.CorrelationWindow)
+	}
+	if cfg.CorrelationMinPods, err = envInt("CORRELATION_MIN_PODS", cfg.CorrelationMinPods); err != nil {
+		return nil, err
+	}
+	if cfg.CorrelationMinPods < 2 {
+		return nil, fmt.Errorf("CORRELATION_MIN_PODS must be at least 2, got %d", cfg.CorrelationMinPods)
+	}
+	if cfg.WatchDeployments, err = envBool("WATCH_DEPLOYMENTS", cfg.WatchDeployments); err != nil {
+		return nil, err
+	}
+	if cfg.DeploymentAvailableFraction, err = envFloat("DEPLOYMENT_AVAILABLE_FRACTION", cfg.DeploymentAvailableFraction); err != nil {
+		return nil, err
+	}
+	if f := cfg.DeploymentAvailableFraction; f <= 0 || f > 1 {
+		return nil, fmt.Errorf("DEPLOYMENT_AVAILABLE_FRACTION must be in (0, 1], got %v", f)
+	}
+	if cfg.DeploymentUnavailableThreshold, err = envDuration("DEPLOYMENT_UNAVAILABLE_THRESHOLD", cfg.DeploymentUnavailableThreshold); err != nil {
+		return nil, err
+	}
+	if cfg.WatchDaemonSets, err = envBool("WATCH_DAEMONSETS", cfg.WatchDaemonSets); err != nil {
+		return nil, err
+	}
+	if cfg.DaemonSetMaxMissing, err = envInt("DAEMONSET_MAX_MISSING", cfg.DaemonSetMaxMissing); err != nil {
+		return nil, err
+	}
+	if cfg.DaemonSetMaxMissing < 0 {
+		return nil, fmt.Errorf("DAEMONSET_MAX_MISSING must not be negative, got %d", cfg.DaemonSetMaxMissing)
+	}
+	if cfg.DaemonSetDegradedThreshold, err = envDuration("DAEMONSET_DEGRADED_THRESHOLD", cfg.DaemonSetDegradedThreshold); err != nil {
+		return nil, err
+	}
+	if cfg.WatchAlertRules, err = envBool("WATCH_ALERT_RULES", cfg.WatchAlertRules); err != nil {
+		return nil, err
+	}
+	cfg.SuppressionConfigMap = envString("SUPPRESSION_CONFIGMAP", cfg.SuppressionConfigMap)
+	if ref := cfg.SuppressionConfigMap; ref != "" {
+		if ns, name, ok := strings.Cut(ref, "/"); !ok || ns == "" || name == "" {
+			return nil, fmt.Errorf("invalid SUPPRESSION_CONFIGMAP %q: expected namespace/name", ref)
+		}
+	}
+	cfg.IgnoreContainers = envList("IGNORE_CONTAINERS")
+	for _, p := range cfg.IgnoreContainers {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid IGNORE_CONTAINERS pattern %q: %w", p, err)
+		}
+	}
+	cfg.IgnoreTerminatedReasons = envList("IGNORE_TERMINATED_REASONS")
+	if cfg.BenignTerminations, err = ParseBenignTerminations(os.Getenv("BENIGN_TERMINATIONS")); err != nil {
+		return nil, err
+	}
+	if _, ok := os.LookupEnv("IDENTITY_LABELS"); ok {
+		cfg.IdentityLabels = envList("IDENTITY_LABELS")
+	}
+	for _, p := range cfg.IdentityLabels {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid IDENTITY_LABELS pattern %q: %w", p, err)
+		}
+	}
+	cfg.IncludeResources = envString("INCLUDE_RESOURCES", cfg.IncludeResources)
+	switch cfg.IncludeResources {
+	case includeResourcesAlways, includeResourcesMemory, includeResourcesNever:
+	default:
+		return nil, fmt.Errorf("invalid INCLUDE_RESOURCES %q: expected always, memory or never", cfg.IncludeResources)
+	}
+	if cfg.IncludeImage, err = envBool("INCLUDE_IMAGE", cfg.IncludeImage); err != nil {
+		return nil, err
+	}
+	if cfg.IncludeEvents, err = envBool("INCLUDE_EVENTS", cfg.IncludeEvents); err != nil {
+		return nil, err
+	}
+	if cfg.IncludeLogs, err = envBool("INCLUDE_LOGS", cfg.IncludeLogs); err != nil {
+		return nil, err
+	}
+	if cfg.LogTailLines, err = envInt("LOG_TAIL_LINES", cfg.LogTailLines); err != nil {
+		return nil, err
+	}
+	if cfg.LogTailLines < 1 {
+		return nil, fmt.Errorf("LOG_TAIL_LINES must be at least 1, got %d", cfg.LogTailLines)
+	}
+	if cfg.LogRetryAttempts, err = envInt("LOG_RETRY_ATTEMPTS", cfg.LogRetryAttempts); err != nil {
+		return nil, err
+	}
+	if cfg.LogRetryAttempts < 0 {
+		return nil, fmt.Errorf("LOG_RETRY_ATTEMPTS must not be negative, got %d", cfg.LogRetryAttempts)
+	}
+	if cfg.LogRetryDelay, err = envDuration("LOG_RETRY_DELAY", cfg.LogRetryDelay); err != nil {
+		return nil, err
+	}
+	if cfg.LogRetryAttempts > 0 && cfg.LogRetryDelay <= 0 {
+		return nil, fmt.Errorf("LOG_RETRY_DELAY must be positive, got %v", cfg.LogRetryDelay)
+	}
+	if cfg.EnrichmentTimeout, err = envDuration("ENRICHMENT_TIMEOUT", cfg.EnrichmentTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.EnrichmentTimeout < 0 {
+		return nil, fmt.Errorf("ENRICHMENT_TIMEOUT must not be negative, got %v", cfg.EnrichmentTimeout)
+	}
+	if cfg.MaxPayloadBytes, err = envInt("MAX_PAYLOAD_BYTES", cfg.MaxPayloadBytes); err != nil {
+		return nil, err
+	}
+	if cfg.MaxPayloadBytes < 0 {
+		return nil, fmt.Errorf("MAX_PAYLOAD_BYTES must not be negative, got %d", cfg.MaxPayloadBytes)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// envString returns the value of the environment variable, or def if it is unset
+func envString(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return def
+}
+
+// envInt parses the environment variable as an integer, or returns def if it is unset
+func envInt(key string, def int) (int, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	return n, nil
+}
+
+// envFloat parses the environment variable as a float, or returns def if it is unset
+func envFloat(key string, def float64) (float64, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	return f, nil
+}
+
+// envBool parses the environment variable as a boolean, or returns def if it is unset
+func envBool(key string, def bool) (bool, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	return b, nil
+}
+
+// envDuration parses the environment variable as a time.Duration, or returns def if it is unset
+func envDuration(key string, def time.Duration) (time.Duration, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	return d, nil
+}
+
+// envList parses a comma-separated list, dropping empty entries
+func envList(key string) []string {
+	var out []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// envSeverity parses the environment variable as a Severity, or returns def if it is unset
+func envSeverity(key string, def Severity) (Severity, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	sev, err := ParseSeverity(v)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return sev, nil
+}
+
+// envSeverityMap parses a comma-separated list of reason=severity pairs on top of the built-in map
+func envSeverityMap(key string) (map[string]Severity, error) {
+	pairs, err := envSeverityPairs(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]Severity, len(defaultReasonSeverities)+len(pairs))
+	for reason, sev := range defaultReasonSeverities {
+		out[reason] = sev
+	}
+	for reason, sev := range pairs {
+		out[reason] = sev
+	}
+	return out, nil
+}
+
+// envSeverityPairs parses a comma-separated list of key=severity pairs
+func envSeverityPairs(key string) (map[string]Severity, error) {
+	out := make(map[string]Severity)
+	for _, pair := range envList(key) {
+		k, raw, ok := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid %s entry %q: expected key=severity", key, pair)
+		}
+		sev, err := ParseSeverity(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q: %w", key, pair, err)
+		}
+		out[k] = sev
+	}
+	return out, nil
+}
+
+// envStringMap parses a comma-separated list of key=value pairs
+func envStringMap(key string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, pair := range envList(key) {
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid %s entry %q: expected key=value", key, pair)
+		}
+		out[k] = strings.TrimSpace(v)
+	}
+	return out, nil
+}
+
+// envDurationMap parses a comma-separated list of key=duration pairs
+func envDurationMap(key string) (map[string]time.Duration, error) {
+	out := make(map[string]time.Duration)
+	v := os.Getenv(key)
+	if v == "" {
+		return out, nil
+	}
+	for _, pair := range strings.Split(v, ",") {
+		k, raw, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid %s entry %q: expected key=duration", key, pair)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q: %w", key, pair, err)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("invalid %s entry %q: duration must not be negative", key, pair)
+		}
+		out[k] = d
+	}
+	return out, nil
+}
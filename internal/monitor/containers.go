@@ -0,0 +1,48 @@
+package monitor
+
+import (
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ignoreContainersAnnotation lists additional container names or globs to ignore for a single pod
+const ignoreContainersAnnotation = "watch-my-pod/ignore-containers"
+
+// ignoredContainers returns the container name patterns whose bad states are ignored for the pod
+func (c *Controller) ignoredContainers(pod *corev1.Pod) []string {
+	v, ok := pod.Annotations[ignoreContainersAnnotation]
+	if !ok {
+		return c.cfg.IgnoreContainers
+	}
+	patterns := append([]string{}, c.cfg.IgnoreContainers...)
+	for _, p := range strings.Split(v, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// matchesAny reports whether name matches any of the exact names or globs
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// containerLooksBad reports whether an ignored container is in a state that would
+// otherwise be checked for an alert, and names it
+func containerLooksBad(status corev1.ContainerStatus) (string, bool) {
+	if w := status.State.Waiting; w != nil && (w.Reason == "CrashLoopBackOff" || imagePullReasons[w.Reason]) {
+		return w.Reason, true
+	}
+	if t := status.State.Terminated; t != nil && t.ExitCode != 0 {
+		return "Terminated(" + t.Reason + ")", true
+	}
+	return "", false
+}
@@ -1,182 +1,826 @@
 package monitor
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt" // <-- ADDED for pod key
-	"io"
 	"log"
-	"net/http"
 	"sync" // <-- ADDED for mutex
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 )
 
-// alertWaitPeriod is the duration to wait before re-alerting for the same pod
+// alertWaitPeriod is the default duration to wait before re-alerting for the same pod
 const alertWaitPeriod = 2 * time.Hour
 
-// Controller holds the clientset and the informer
+// Controller holds the clientset and the informers
 type Controller struct {
 	Clientset kubernetes.Interface
-	Informer  cache.SharedIndexInformer
+
+	// Informers watch pods, one per watched namespace (or a single cluster-wide one)
+	Informers []cache.SharedIndexInformer
+
+	// auxInformers watch supporting resources and are synced before the controller starts alerting
+	auxInformers []cache.SharedIndexInformer
+
+	// deployments are the optional Deployment informers, one per watched namespace
+	deployments []cache.SharedIndexInformer
+
+	// daemonSets are the optional DaemonSet informers, one per watched namespace
+	daemonSets []cache.SharedIndexInformer
+
+	// workloadBelow records when each workload was first seen below its replica target
+	workloadBelow map[string]time.Time
+	workloadMu    sync.Mutex
+
+	// liveness counts liveness probe failures per pod
+	liveness livenessTracker
+
+	// imagePulls counts failed image pull attempts per container
+	imagePulls imagePullTracker
+
+	// nodeGroups buffer pod alerts per node while NODE_CORRELATION_WINDOW runs
+	nodeGroups   map[string][]pendingAlert
+	nodeTimers   map[string]*time.Timer
+	nodeGroupsMu sync.Mutex
+
+	// correlationGroups buffer pod alerts per CORRELATION_LABEL value while CORRELATION_WINDOW runs
+	correlationGroups   map[string][]pendingAlert
+	correlationTimers   map[string]*time.Timer
+	correlationGroupsMu sync.Mutex
+
+	// draining is set, under both group locks, once shutdown has flushed the
+	// buffered groups; later alerts are delivered at once
+	draining bool
+	// flushes tracks the window timers that fired and are still sending
+	flushes sync.WaitGroup
+
+	cfg   *Config
+	clock Clock
 
 	// --- NEW: Cache for rate limiting ---
-	alertCache map[string]time.Time
-	cacheMutex sync.RWMutex
-}
+	alertCache AlertCache
 
-// NewController creates a new controller
-func NewController(clientset *kubernetes.Clientset) *Controller {
+	// notifiers receive every alert that passes deduplication
+	notifiers []Notifier
+
+	// evaluators are custom bad-state checks run after the built-in ones
+	evaluators []PodEvaluator
+
+	// sampler, when set, writes a sample of agent exchanges for offline review
+	sampler *ResponseSampler
+
+	// audit, when set, records every alerting decision
+	audit *AuditLog
+
+	// ctx is cancelled when Run's stop channel closes
+	ctx context.Context
+
+	// addsArmed is set once the cache has synced and the startup grace has elapsed.
+	// Until then, adds only record state; the periodic recheck alerts on them afterwards.
+	addsArmed atomic.Bool
+
+	// failing tracks every pod currently in a bad state
+	failing   map[string]failingPod
+	failingMu sync.Mutex
+	// failingLimit caps the size of failing (MAX_FAILING_PODS)
+	failingLimit *trackingLimit
+
+	// resolvedAt is when each pod last had a resolved alert sent, guarded by failingMu
+	resolvedAt map[string]time.Time
+
+	// history is the last alert sent for each pod, served on /alerts
+	history   map[string]*alertRecord
+	historyMu sync.RWMutex
+
+	// suppressions are the rules loaded from the suppression ConfigMap
+	suppressions atomic.Pointer[[]SuppressionRule]
+
+	// dynamic watches PodAlertRules; alertRules is the current rule set
+	dynamic       dynamic.Interface
+	ruleInformers []cache.SharedIndexInformer
+	alertRules    atomic.Pointer[[]*alertRule]
 
-	// --- THIS IS THE FIXED LINE ---
-	factory := informers.NewSharedInformerFactory(clientset, 10*time.Minute)
-	podInformer := factory.Core().V1().Pods().Informer()
+	// alertsSent counts alerts delivered since the last heartbeat
+	alertsSent atomic.Int64
 
+	// synced is set once the informer caches have synced, for /readyz
+	synced atomic.Bool
+
+	// stats are the totals reported by the shutdown summary
+	stats runStats
+
+	// forbidden records the namespace/resource pairs we already warned about lacking access to
+	forbidden sync.Map
+
+	// caps are the optional APIs found at startup
+	caps apiCapabilities
+
+	// tracer starts the span of each alert
+	tracer trace.Tracer
+}
+
+// NewController creates a new controller
+func NewController(clientset kubernetes.Interface, cfg *Config, notifiers []Notifier, opts ...Option) *Controller {
 	c := &Controller{
 		Clientset: clientset,
-		Informer:  podInformer,
+		cfg:       cfg,
+		clock:     realClock{},
+
+		notifiers: notifiers,
+		ctx:       context.Background(),
+		history:   make(map[string]*alertRecord),
+		failing:   make(map[string]failingPod),
+
+		failingLimit: &trackingLimit{set: "failing_pods", max: cfg.MaxFailingPods},
 
-		// --- NEW: Initialize the cache and mutex ---
-		alertCache: make(map[string]time.Time),
-		cacheMutex: sync.RWMutex{},
+		resolvedAt: make(map[string]time.Time),
+
+		workloadBelow: make(map[string]time.Time),
+		nodeGroups:    make(map[string][]pendingAlert),
+		nodeTimers:    make(map[string]*time.Timer),
+
+		correlationGroups: make(map[string][]pendingAlert),
+		correlationTimers: make(map[string]*time.Timer),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	if c.alertCache == nil {
+		// --- NEW: Initialize the cache ---
+		c.alertCache = newMemoryAlertCache(c.clock, cfg.MaxAlertCacheEntries)
+	} else if cc, ok := c.alertCache.(clockUser); ok {
+		cc.SetClock(c.clock)
+	}
+	if c.tracer == nil {
+		c.tracer = defaultTracer()
+	}
+	c.detectCapabilities()
 
-	podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    c.onAdd,
-		UpdateFunc: c.onUpdate,
-	})
+	for _, n := range notifiers {
+		if cu, ok := n.(clockUser); ok {
+			cu.SetClock(c.clock)
+		}
+	}
+	if cfg.CaptureAgentResponse || cfg.AgentMaxSuppressFor > 0 || c.sampler != nil {
+		for _, n := range notifiers {
+			if rc, ok := n.(responseCapturer); ok {
+				rc.SetResponseHandler(c.handleAgentResponse)
+			}
+		}
+	}
+
+	// Watching a set of namespaces takes one informer each, so namespaced RBAC is enough
+	namespaces := cfg.WatchNamespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	for _, ns := range namespaces {
+		// --- THIS IS THE FIXED LINE ---
+		// Jitter the resync so replicas and restarts don't relist in lockstep
+		factory := informers.NewSharedInformerFactoryWithOptions(clientset, wait.Jitter(cfg.ResyncPeriod, 0.1),
+			informers.WithNamespace(ns))
+		podInformer := factory.Core().V1().Pods().Informer()
+		podInformer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+			AddFunc:    c.onAdd,
+			UpdateFunc: c.onUpdate,
+			DeleteFunc: c.onDelete,
+		})
+		c.Informers = append(c.Informers, podInformer)
+
+		if cfg.WatchDeployments {
+			c.watchDeployments(factory)
+		}
+		if cfg.WatchDaemonSets {
+			c.watchDaemonSets(factory)
+		}
+		if (cfg.LivenessEvents || cfg.SandboxEvents) && c.caps.events {
+			c.watchPodEvents(clientset, ns)
+		}
+		if cfg.WatchAlertRules && c.dynamic != nil {
+			c.watchAlertRules(ns)
+		}
+	}
+
+	if cfg.SuppressionConfigMap != "" {
+		c.watchSuppressions(clientset, cfg.SuppressionConfigMap)
+	}
 
 	return c
 }
 
-// Run starts the controller's informer
+// Run starts the controller's informers
 func (c *Controller) Run(stopCh <-chan struct{}) {
-	// ... (this function is unchanged)
 	log.Println("Starting monitor controller...")
-	go c.Informer.Run(stopCh)
+	c.ctx = wait.ContextForChannel(stopCh)
+	c.stats.started = c.clock.Now()
+
+	var synced []cache.InformerSynced
+	for _, inf := range c.Informers {
+		c.installWatchdog("pod", inf)
+		go inf.Run(stopCh)
+		synced = append(synced, inf.HasSynced)
+	}
+	for _, inf := range c.auxInformers {
+		c.installWatchdog("auxiliary", inf)
+		go inf.Run(stopCh)
+		synced = append(synced, inf.HasSynced)
+	}
 
-	if !cache.WaitForCacheSync(stopCh, c.Informer.HasSynced) {
+	if !cache.WaitForCacheSync(stopCh, synced...) {
 		log.Fatalf("failed to sync cache")
 		return
 	}
 	log.Println("Controller cache synced")
+	c.synced.Store(true)
+
+	time.AfterFunc(c.cfg.StartupGrace, func() {
+		c.addsArmed.Store(true)
+		// Events listed before the pods synced may belong to pods that are gone
+		c.liveness.prune(func(podKey string, uid types.UID) bool {
+			pod, ok := c.getPod(podKey)
+			return ok && pod.UID == uid
+		})
+	})
+
+	// Pods stuck in a state stop producing updates, so re-evaluate them periodically
+	go wait.Until(c.recheckPods, c.cfg.RecheckInterval, stopCh)
+	go wait.Until(c.recheckDeployments, c.cfg.RecheckInterval, stopCh)
+	go wait.Until(c.recheckDaemonSets, c.cfg.RecheckInterval, stopCh)
+	go wait.Until(c.checkChronic, c.cfg.RecheckInterval, stopCh)
+
+	if c.cfg.HeartbeatInterval > 0 {
+		go wait.Until(c.heartbeat, c.cfg.HeartbeatInterval, stopCh)
+	}
+	if c.cfg.InventoryFile != "" {
+		go wait.Until(c.writeInventory, c.cfg.InventoryInterval, stopCh)
+	}
 
 	<-stopCh
 	log.Println("Stopping monitor controller...")
+	c.drainBuffered()
+	if c.cfg.InventoryFile != "" {
+		c.writeInventory()
+	}
+	c.logSummary()
 }
 
-// onAdd is called when a pod is added
-func (c *Controller) onAdd(obj interface{}) {
-	pod := obj.(*corev1.Pod)
-	if isBad, reason := checkPodBadState(pod); isBad {
-		log.Printf("TRIGGER_CHECK: New pod %s/%s is in bad state: %s", pod.Namespace, pod.Name, reason)
-		c.checkAndTrigger(pod, reason)
+// onAdd is called when a pod is added, including for every pod in the initial list
+func (c *Controller) onAdd(obj interface{}, isInInitialList bool) {
+	pod, ok := asPod(obj)
+	if !ok {
+		return
+	}
+	podEvents.WithLabelValues("add").Inc()
+	isBad, state := c.checkPodBadState(pod)
+	observePodState(isBad)
+	if isBad {
+		c.markFailing(pod, state.Reason)
+		if isInInitialList || !c.addsArmed.Load() {
+			// Already-bad pods from the initial list are alerted on by the recheck once the grace elapses
+			return
+		}
+		log.Printf("TRIGGER_CHECK: New pod %s/%s is in bad state: %s", pod.Namespace, pod.Name, state.Reason)
+		c.checkAndTrigger(pod, state)
 	}
 }
 
 // onUpdate is called when a pod is modified
 func (c *Controller) onUpdate(oldObj, newObj interface{}) {
-	oldPod := oldObj.(*corev1.Pod)
-	newPod := newObj.(*corev1.Pod)
-
-	wasBad, _ := checkPodBadState(oldPod)
-	isBad, reason := checkPodBadState(newPod)
+	newPod, ok := asPod(newObj)
+	if !ok {
+		return
+	}
+	// Without a usable old pod, treat the update as a fresh add; the cooldown stops a duplicate
+	wasBad, escalated := false, false
+	if oldPod, ok := asPod(oldObj); ok {
+		var oldState badState
+		wasBad, oldState = c.checkPodBadState(oldPod)
+		escalated = oldState.Reason == crashLoopWarningReason
+	}
+	isBad, state := c.checkPodBadState(newPod)
+	podEvents.WithLabelValues("update").Inc()
+	observePodState(isBad)
+
+	if isBad {
+		c.markFailing(newPod, state.Reason)
+	} else {
+		c.markRecovered(newPod)
+	}
 
 	if !wasBad && isBad {
-		log.Printf("TRIGGER_CHECK: Pod %s/%s has entered bad state: %s", newPod.Namespace, newPod.Name, reason)
-		c.checkAndTrigger(newPod, reason)
+		log.Printf("TRIGGER_CHECK: Pod %s/%s has entered bad state: %s", newPod.Namespace, newPod.Name, state.Reason)
+		c.checkAndTrigger(newPod, state)
+	} else if escalated && isBad && state.Reason != crashLoopWarningReason {
+		// Crossing the alert threshold after the early warning must page now, not on the next recheck
+		log.Printf("TRIGGER_CHECK: Pod %s/%s has escalated from %s to %s", newPod.Namespace, newPod.Name, crashLoopWarningReason, state.Reason)
+		c.checkAndTrigger(newPod, state)
 	}
 }
 
+// onDelete is called when a pod is deleted
+func (c *Controller) onDelete(obj interface{}) {
+	pod, ok := asPod(obj)
+	if !ok {
+		return
+	}
+	podEvents.WithLabelValues("delete").Inc()
+	c.forgetFailing(pod)
+	c.liveness.forget(pod.Namespace + "/" + pod.Name)
+	c.imagePulls.forget(pod.Namespace + "/" + pod.Name)
+	c.forgetAudit(pod.Namespace + "/" + pod.Name)
+}
+
+// observePodState counts an evaluated pod state toward the healthy/bad ratio
+func observePodState(isBad bool) {
+	if isBad {
+		podObservations.WithLabelValues("bad").Inc()
+	} else {
+		podObservations.WithLabelValues("healthy").Inc()
+	}
+}
+
+// asPod unwraps an informer object into a pod, logging anything unexpected.
+// The delta FIFO can hand out tombstones during relists, so handlers must
+// never assert the type blindly.
+func asPod(obj interface{}) (*corev1.Pod, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		log.Printf("WARNING: Skipping unexpected object of type %T from the pod informer", obj)
+		return nil, false
+	}
+	return pod, true
+}
+
+// recheckPods re-evaluates every pod in the informer store.
+// Time-based failures (e.g. a container stuck in ContainerCreating) only
+// become bad after a deadline, when the pod may no longer be changing.
+func (c *Controller) recheckPods() {
+	if !c.addsArmed.Load() {
+		return
+	}
+	for _, obj := range c.listPods() {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok {
+			continue
+		}
+		isBad, state := c.checkPodBadState(pod)
+		if !isBad {
+			continue
+		}
+		c.markFailing(pod, state.Reason)
+		if !c.recentlyAlerted(pod, state.Reason) {
+			c.checkAndTrigger(pod, state)
+		}
+	}
+}
+
+// listPods returns every pod in the informer stores
+func (c *Controller) listPods() []interface{} {
+	var pods []interface{}
+	for _, inf := range c.Informers {
+		pods = append(pods, inf.GetStore().List()...)
+	}
+	return pods
+}
+
+// recentlyAlerted reports whether an alert for the pod would be suppressed by the cache
+func (c *Controller) recentlyAlerted(pod *corev1.Pod, reason string) bool {
+	should, _ := c.shouldAlert(c.dedupKey(pod, reason), reason)
+	return !should
+}
+
+// passesGates runs the filters and rules that can hold back an alert for the pod,
+// logging why one did. It returns the PodAlertRule that applies, if any.
+// Every alert about a bad pod goes through it, so a silenced pod stays silent.
+func (c *Controller) passesGates(pod *corev1.Pod, state badState) (*alertRule, bool) {
+	podKey := pod.Namespace + "/" + pod.Name
+
+	// Give young pods time to settle; the periodic recheck picks them up once they are old enough
+	if age := c.clock.Since(pod.CreationTimestamp.Time); age < c.cfg.MinPodAge {
+		log.Printf("IGNORED ALERT for %s. Pod is %v old (minimum age %v).", podKey, age.Round(time.Second), c.cfg.MinPodAge)
+		c.auditPod(pod, state.Reason, auditIgnored, "MIN_POD_AGE", fmt.Sprintf("pod is %v old", age.Round(time.Second)))
+		return nil, false
+	}
+
+	// Some reasons are often transient, e.g. image pulls onto a node the autoscaler just
+	// added; the recheck alerts once the pod has stayed bad for the whole debounce
+	if debounce := c.debounceFor(state.Reason); debounce > 0 {
+		if bad, ok := c.badFor(podKey, state); ok && bad < debounce {
+			log.Printf("IGNORED ALERT for %s (%s). Pod has been bad for %v (debounce %v).", podKey, state.Reason, bad.Round(time.Second), debounce)
+			c.auditPod(pod, state.Reason, auditIgnored, "REASON_DEBOUNCE", fmt.Sprintf("bad for %v of %v", bad.Round(time.Second), debounce))
+			return nil, false
+		}
+	}
+
+	// A terminating pod's containers fail as part of the teardown. Only the alert is
+	// skipped: recovery tracking and resolved notifications happen before this point.
+	if c.cfg.IgnoreTerminatingPods && pod.DeletionTimestamp != nil {
+		log.Printf("IGNORED ALERT for %s (%s). Pod is being deleted.", podKey, state.Reason)
+		c.auditPod(pod, state.Reason, auditIgnored, "IGNORE_TERMINATING_PODS", "pod is being deleted")
+		return nil, false
+	}
+
+	// The recheck sees a suppressed pod again every interval; only a new suppression is logged and counted
+	if rule := c.matchSuppression(pod, state.Reason); rule != nil {
+		why := fmt.Sprintf("suppression rule %s", rule)
+		if !c.repeatHoldBack(podKey, state.Reason+"|"+why) {
+			log.Printf("SUPPRESSED ALERT for %s by %s.", podKey, why)
+			c.stats.suppressed.Add(1)
+		}
+		c.auditPod(pod, state.Reason, auditSuppressed, why, "")
+		return nil, false
+	}
+
+	if w, ok := c.inMaintenance(pod.Namespace); ok {
+		why := fmt.Sprintf("maintenance window %s", w)
+		if !c.repeatHoldBack(podKey, state.Reason+"|"+why) {
+			log.Printf("SUPPRESSED ALERT for %s (%s) during %s.", podKey, state.Reason, why)
+			c.stats.suppressed.Add(1)
+		}
+		c.auditPod(pod, state.Reason, auditSuppressed, why, "")
+		return nil, false
+	}
+
+	rule := c.matchAlertRule(pod, state.Reason)
+	if rule != nil && rule.ignore {
+		why := fmt.Sprintf("PodAlertRule %s", rule)
+		if !c.repeatHoldBack(podKey, state.Reason+"|"+why) {
+			log.Printf("SUPPRESSED ALERT for %s by %s.", podKey, why)
+			c.stats.suppressed.Add(1)
+		}
+		c.auditPod(pod, state.Reason, auditSuppressed, why, "")
+		return nil, false
+	}
+	return rule, true
+}
+
 // --- NEW FUNCTION: checkAndTrigger ---
-func (c *Controller) checkAndTrigger(pod *corev1.Pod, reason string) {
+func (c *Controller) checkAndTrigger(pod *corev1.Pod, state badState) {
 	podKey := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+	rule, ok := c.passesGates(pod, state)
+	if !ok {
+		return
+	}
 
-	c.cacheMutex.RLock()
-	lastAlertTime, exists := c.alertCache[podKey]
-	c.cacheMutex.RUnlock()
-
-	if exists && time.Since(lastAlertTime) < alertWaitPeriod {
+	cooldown := c.podCooldown(pod, state.Reason, rule)
+
+	// Decide and reserve the cache entry in one step, so an Add and an Update racing
+	// for the same pod can't both send; the reservation is undone if delivery fails
+	dedupKey := c.dedupKey(pod, state.Reason)
+	res := c.reserveAlert(dedupKey, state.Reason, cooldown)
+	kind := res.Kind
+	dedupDecisions.WithLabelValues(dedupDecision(res.Won, kind)).Inc()
+	if !res.Won && !res.Existed {
+		c.stats.suppressed.Add(1)
+		log.Printf("SUPPRESSED ALERT for %s. No alert cache entry could be reserved for it.", podKey)
+		c.auditPod(pod, state.Reason, auditSuppressed, "alert_cache", "no alert cache entry could be reserved")
+		return
+	}
+	if !res.Won {
+		c.stats.suppressed.Add(1)
 		log.Printf(
-			"SUPPRESSED ALERT for %s. Last alert was at %v (within %v).",
+			"SUPPRESSED ALERT for %s. Last alert was at %v (suppressed until %v).",
 			podKey,
-			lastAlertTime,
-			alertWaitPeriod,
+			res.Last.At,
+			res.Last.Until,
 		)
+		c.auditPod(pod, state.Reason, auditSuppressed, "cooldown", fmt.Sprintf("last alert at %s, suppressed until %s", res.Last.At.UTC().Format(time.RFC3339), res.Last.Until.UTC().Format(time.RFC3339)))
 		return
 	}
+	c.setFailingDedupKey(podKey, dedupKey)
+
+	ctx, span := c.startAlertSpan(pod, state.Reason, kind)
+	defer span.End()
+	countTriggered(ctx, kind)
+
+	// A stuck ContainerCreating is usually a storage problem; surface the event reason if there is one
+	reason := state.Reason
+	if reason == containerCreatingTimeoutReason {
+		if volumeReason, ok := c.volumeFailureReason(ctx, pod); ok {
+			reason = volumeReason
+		}
+	}
+
+	ownerKind, ownerName := podOwner(pod)
+	alert := &Alert{
+		Namespace: pod.Namespace,
+		PodName:   pod.Name,
+		podUID:    string(pod.UID),
+		NodeName:  pod.Spec.NodeName,
+		OwnerKind: ownerKind,
+		OwnerName: ownerName,
+		Labels:    c.identityLabels(pod),
+		Reason:    reason,
+		Severity:  c.ruleSeverity(pod, reason, rule),
+		Kind:      kind,
+		Detail:    state.Detail,
+		Container: state.Container,
+	}
+	if !state.Since.IsZero() {
+		since := state.Since.UTC()
+		alert.FailedSince = &since
+	}
+	c.routeAlert(pod, alert)
+	c.enrichAlert(ctx, pod, state.Container, alert)
+	c.fitPayload(alert)
+
+	alert.cacheKey = dedupKey
+	alert.occurredAt = res.Entry.At
+	if c.audit != nil {
+		alert.audit = &auditRecord{Cooldown: cooldown.String()}
+		if rule != nil {
+			alert.audit.Rule = fmt.Sprintf("PodAlertRule %s", rule)
+		}
+	}
+
+	p := pendingAlert{ctx: ctx, key: dedupKey, alert: alert, res: res}
+	// An operator-chosen root cause is a better grouping than the node, so it goes first
+	if value, ok := c.correlationValue(pod.Labels); ok {
+		c.bufferForCorrelation(value, p)
+		return
+	}
+	if c.cfg.NodeCorrelation && pod.Spec.NodeName != "" {
+		c.bufferForNode(pod.Spec.NodeName, p)
+		return
+	}
+	c.deliver(p)
+}
+
+// pendingAlert is a pod alert whose cache entry is reserved but which is not sent yet
+type pendingAlert struct {
+	// ctx carries the alert's span, so delivery is traced under it
+	ctx   context.Context
+	key   string
+	alert *Alert
+
+	// res is the reservation, released if delivery fails
+	res Reservation
+}
+
+// deliver sends a pod alert, releasing its reservation if no notifier delivered it
+func (c *Controller) deliver(p pendingAlert) {
+	if !c.notify(p.ctx, p.alert) {
+		log.Printf("Alert for %s was not delivered; it will be retried on the next bad-state observation", p.key)
+		c.cacheUndo(p.key, p.res)
+	}
+}
+
+// badStateDetailLimit bounds the length of the detail message attached to an alert
+const badStateDetailLimit = 512
 
-	c.cacheMutex.Lock()
-	c.alertCache[podKey] = time.Now()
-	c.cacheMutex.Unlock()
+// customEvaluatorReason is reported when a PodEvaluator flags a pod without giving a reason
+const customEvaluatorReason = "CustomCheckFailed"
 
-	c.triggerAnalysis(pod, reason)
+// evictedReason is the pod status reason set when the kubelet evicts a pod under node pressure
+const evictedReason = "Evicted"
+
+// badState describes why a pod is considered bad
+type badState struct {
+	Reason string
+	// Container is the failing container, empty for pod-level failures
+	Container string
+	// Detail is the human-readable message behind the reason, if any
+	Detail string
+	// Since is the best available estimate of when the failure started; zero if unknown
+	Since time.Time
+}
+
+// crashLoopWarningReason is the early warning for a crash loop that has not reached CRASHLOOP_ALERT_RESTARTS yet
+const crashLoopWarningReason = "CrashLoopBackOffWarning"
+
+// imagePullReasons are the waiting reasons of a container whose image cannot be pulled
+var imagePullReasons = map[string]bool{
+	"ImagePullBackOff": true,
+	"ErrImagePull":     true,
+}
+
+// badPhaseChoices are the phases BAD_PHASES may list. Pending, Running and
+// Succeeded are normal stages of a healthy pod.
+var badPhaseChoices = map[string]bool{
+	string(corev1.PodFailed):  true,
+	string(corev1.PodUnknown): true,
+}
+
+// badPhase reports whether the pod's phase is one of BAD_PHASES
+func (c *Controller) badPhase(pod *corev1.Pod) bool {
+	for _, phase := range c.cfg.BadPhases {
+		if string(pod.Status.Phase) == phase {
+			return true
+		}
+	}
+	return false
 }
 
 // checkPodBadState checks for various failure conditions
-func checkPodBadState(pod *corev1.Pod) (bool, string) {
-	if pod.Status.Phase == corev1.PodFailed {
-		return true, "PodFailed"
+func (c *Controller) checkPodBadState(pod *corev1.Pod) (bool, badState) {
+	if pod.Status.Phase == corev1.PodFailed && c.badPhase(pod) {
+		// Node-pressure evictions are not app crashes; keep them distinguishable
+		if pod.Status.Reason == evictedReason {
+			return true, badState{
+				Reason: evictedReason,
+				Detail: truncate(pod.Status.Message, badStateDetailLimit),
+				Since:  podFailedSince(pod),
+			}
+		}
+		if gracefullyTerminated(pod) {
+			return false, badState{}
+		}
+		// IGNORE_TERMINATED_REASONS and BENIGN_TERMINATIONS apply to the pod's exits too
+		if c.benignPodFailure(pod) {
+			c.auditPod(pod, "PodFailed", auditIgnored, "IGNORE_TERMINATED_REASONS or BENIGN_TERMINATIONS", "every terminated container exited benignly")
+			return false, badState{}
+		}
+		return true, badState{Reason: "PodFailed", Since: podFailedSince(pod)}
+	}
+	if c.badPhase(pod) {
+		// Unknown usually means the node stopped reporting, so there is no container state to go on
+		return true, badState{
+			Reason: "Pod" + string(pod.Status.Phase),
+			Detail: truncate(pod.Status.Message, badStateDetailLimit),
+			Since:  conditionFalseSince(pod, corev1.PodReady),
+		}
 	}
 
+	podKey := pod.Namespace + "/" + pod.Name
+	ignored := c.ignoredContainers(pod)
 	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if matchesAny(ignored, containerStatus.Name) {
+			if reason, bad := containerLooksBad(containerStatus); bad {
+				c.auditPod(pod, reason, auditIgnored, "IGNORE_CONTAINERS", "container "+containerStatus.Name)
+			}
+			continue
+		}
+		if w := containerStatus.State.Waiting; w == nil || !imagePullReasons[w.Reason] {
+			c.imagePulls.reset(podKey, containerStatus.Name)
+		}
 		if containerStatus.State.Waiting != nil {
 			reason := containerStatus.State.Waiting.Reason
-			if reason == "CrashLoopBackOff" || reason == "ImagePullBackOff" || reason == "ErrImagePull" {
-				return true, reason
+			if imagePullReasons[reason] {
+				// A registry blip fails a pull or two; only repeated failures are worth an alert
+				attempts := c.imagePulls.observe(podKey, containerStatus.Name, reason)
+				if attempts < c.cfg.ImagePullMinFailures {
+					continue
+				}
+				// The message tells a missing tag apart from a registry auth or DNS problem
+				detail := containerStatus.State.Waiting.Message
+				if c.cfg.ImagePullMinFailures > 1 {
+					// A distinct reason, so routing, severities and cooldowns can tell it from a single failed pull
+					reason = repeatedImagePullReason
+					detail = fmt.Sprintf("%d failed pulls: %s", attempts, detail)
+				}
+				return true, badState{
+					Reason:    reason,
+					Container: containerStatus.Name,
+					Detail:    truncate(detail, badStateDetailLimit),
+					Since:     conditionFalseSince(pod, corev1.ContainersReady),
+				}
+			}
+			if reason == "CrashLoopBackOff" {
+				// Restarting after a known-benign exit, e.g. a sidecar reloading its config
+				if rule := c.benignTerminationRule(containerStatus.LastTerminationState.Terminated); rule != "" {
+					c.auditPod(pod, reason, auditIgnored, rule, "container "+containerStatus.Name+" last exited with "+containerStatus.LastTerminationState.Terminated.Reason)
+					continue
+				}
+				// Below CRASHLOOP_ALERT_RESTARTS a crash loop is at most an early warning
+				if restarts := int(containerStatus.RestartCount); restarts < c.cfg.CrashLoopAlertRestarts {
+					if c.cfg.CrashLoopWarnRestarts == 0 || restarts < c.cfg.CrashLoopWarnRestarts {
+						continue
+					}
+					reason = crashLoopWarningReason
+				}
+				// Waiting states carry no timestamp; the last crash is the most meaningful one
+				since := conditionFalseSince(pod, corev1.ContainersReady)
+				if last := containerStatus.LastTerminationState.Terminated; last != nil {
+					since = last.FinishedAt.Time
+				}
+				return true, badState{Reason: reason, Container: containerStatus.Name, Since: since}
+			}
+			if reason == containerCreatingReason && c.cfg.ContainerCreatingTimeout > 0 &&
+				c.clock.Since(podScheduledTime(pod)) > c.cfg.ContainerCreatingTimeout {
+				return true, badState{Reason: containerCreatingTimeoutReason, Container: containerStatus.Name, Since: podScheduledTime(pod)}
+			}
+		}
+		// Known-harmless exits are healthy whatever their exit code
+		if t := containerStatus.State.Terminated; t != nil && t.ExitCode != 0 {
+			if rule := c.benignTerminationRule(t); rule != "" {
+				c.auditPod(pod, "Terminated("+t.Reason+")", auditIgnored, rule, "container "+containerStatus.Name)
+				continue
 			}
 		}
-		if containerStatus.State.Terminated != nil {
-			if containerStatus.State.Terminated.Reason == "Error" {
-				return true, "Terminated(Error)"
+		if containerStatus.State.Terminated != nil && !c.benignTermination(containerStatus.State.Terminated) {
+			if containerStatus.State.Terminated.Reason == "Error" && !expectedExit(pod, containerStatus.State.Terminated) {
+				return true, badState{
+					Reason:    "Terminated(Error)",
+					Container: containerStatus.Name,
+					Since:     containerStatus.State.Terminated.FinishedAt.Time,
+				}
 			}
 		}
 	}
-	return false, ""
+	if isBad, state := c.checkStuckWaiting(pod, ignored); isBad {
+		return true, state
+	}
+	if isBad, state := c.checkPodConditions(pod); isBad {
+		return true, state
+	}
+	for _, eval := range c.evaluators {
+		if isBad, reason := eval(pod); isBad {
+			if reason == "" {
+				reason = customEvaluatorReason
+			}
+			return true, badState{Reason: reason}
+		}
+	}
+	return false, badState{}
 }
 
-// triggerAnalysis calls our Python AI agent service
-func (c *Controller) triggerAnalysis(pod *corev1.Pod, reason string) {
-	agentURL := "http://localhost:8000/summarize-pod"
+// Graceful node shutdown marks the pods it terminates with these reasons
+var nodeShutdownReasons = map[string]bool{
+	"NodeShutdown": true,
+	"Terminated":   true,
+}
 
-	log.Printf("Triggering analysis for pod: %s/%s (Reason: %s)", pod.Namespace, pod.Name, reason)
+// sigtermExitCode is the exit code of a process killed by SIGTERM
+const sigtermExitCode = 128 + 15
 
-	payload := map[string]string{
-		"namespace": pod.Namespace,
-		"pod_name":  pod.Name,
-		"reason":    reason,
+// gracefullyTerminated reports whether the pod was stopped on purpose, by a
+// node shutdown, a drain or preemption, rather than failing on its own
+func gracefullyTerminated(pod *corev1.Pod) bool {
+	if nodeShutdownReasons[pod.Status.Reason] {
+		return true
 	}
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("ERROR: Failed to marshal JSON for pod %s: %v", pod.Name, err)
-		return
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.DisruptionTarget && cond.Status == corev1.ConditionTrue {
+			return true
+		}
 	}
+	return false
+}
 
-	req, err := http.NewRequest("POST", agentURL, bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		log.Printf("ERROR: Failed to create request for pod %s: %v", pod.Name, err)
-		return
+// expectedExit reports whether a container's error exit is the expected result of stopping the pod
+func expectedExit(pod *corev1.Pod, terminated *corev1.ContainerStateTerminated) bool {
+	if gracefullyTerminated(pod) {
+		return true
 	}
-	req.Header.Set("Content-Type", "application/json")
+	// A process that doesn't handle SIGTERM exits with 143 when its pod is deleted
+	return pod.DeletionTimestamp != nil && terminated.ExitCode == sigtermExitCode
+}
 
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("ERROR: Failed to send request to agent for pod %s: %v", pod.Name, err)
-		return
+// podFailedSince estimates when a Failed pod failed: the last container to
+// terminate, else when the pod stopped being Ready, else when it started
+func podFailedSince(pod *corev1.Pod) time.Time {
+	var latest time.Time
+	for _, cs := range pod.Status.ContainerStatuses {
+		if t := cs.State.Terminated; t != nil && t.FinishedAt.After(latest) {
+			latest = t.FinishedAt.Time
+		}
+	}
+	if !latest.IsZero() {
+		return latest
 	}
-	defer resp.Body.Close()
+	if since := conditionFalseSince(pod, corev1.PodReady); !since.IsZero() {
+		return since
+	}
+	if pod.Status.StartTime != nil {
+		return pod.Status.StartTime.Time
+	}
+	return time.Time{}
+}
 
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("ERROR: Agent service returned non-200 status: %s", resp.Status)
-		body, _ := io.ReadAll(resp.Body)
-		log.Printf("Agent error response: %s", string(body))
-		return
+// conditionFalseSince returns when the pod condition last became false, or zero if it isn't false
+func conditionFalseSince(pod *corev1.Pod, condType corev1.PodConditionType) time.Time {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == condType && cond.Status == corev1.ConditionFalse {
+			return cond.LastTransitionTime.Time
+		}
 	}
+	return time.Time{}
+}
 
-	log.Printf("Successfully triggered analysis for %s/%s. Agent responded: %s", pod.Namespace, pod.Name, resp.Status)
+// truncate shortens s to at most limit bytes, marking that it was cut
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	const marker = "...(truncated)"
+	cut := limit - len(marker)
+	// Don't split a multi-byte character
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + marker
 }
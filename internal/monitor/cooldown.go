@@ -0,0 +1,54 @@
+package monitor
+
+import (
+	"log"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// cooldownAnnotation overrides the re-alert cooldown of a single pod
+const cooldownAnnotation = "watch-my-pod/cooldown"
+
+// cooldownFor returns how long to wait before re-alerting for the pod's reason.
+// Precedence: pod annotation, then reason override, then namespace override, then the global default.
+func (c *Controller) cooldownFor(pod *corev1.Pod, reason string) time.Duration {
+	if d, ok := annotatedCooldown(pod); ok {
+		return d
+	}
+	if d, ok := c.cfg.ReasonCooldowns[reason]; ok {
+		return d
+	}
+	return c.namespaceCooldown(pod.Namespace)
+}
+
+// podCooldown is cooldownFor with the cooldown of the PodAlertRule matching the
+// pod, if any; only a valid pod annotation wins over the rule
+func (c *Controller) podCooldown(pod *corev1.Pod, reason string, rule *alertRule) time.Duration {
+	if _, annotated := annotatedCooldown(pod); rule != nil && rule.cooldown > 0 && !annotated {
+		return rule.cooldown
+	}
+	return c.cooldownFor(pod, reason)
+}
+
+// annotatedCooldown returns the pod's watch-my-pod/cooldown annotation, if it is valid
+func annotatedCooldown(pod *corev1.Pod) (time.Duration, bool) {
+	v, ok := pod.Annotations[cooldownAnnotation]
+	if !ok {
+		return 0, false
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		log.Printf("WARNING: Ignoring invalid %s annotation %q on pod %s/%s", cooldownAnnotation, v, pod.Namespace, pod.Name)
+		return 0, false
+	}
+	return d, true
+}
+
+// namespaceCooldown returns the cooldown of the namespace, falling back to the global default
+func (c *Controller) namespaceCooldown(namespace string) time.Duration {
+	if d, ok := c.cfg.NamespaceCooldowns[namespace]; ok {
+		return d
+	}
+	return c.cfg.AlertCooldown
+}
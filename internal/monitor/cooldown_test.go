@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodCooldown(t *testing.T) {
+	c := &Controller{cfg: &Config{
+		AlertCooldown:   time.Hour,
+		ReasonCooldowns: map[string]time.Duration{"ImagePullBackOff": 12 * time.Hour},
+	}}
+	rule := &alertRule{namespace: "shop", name: "quiet", cooldown: 3 * time.Hour}
+
+	tests := []struct {
+		name       string
+		annotation string
+		rule       *alertRule
+		reason     string
+		want       time.Duration
+	}{
+		{name: "default", reason: "OOMKilled", want: time.Hour},
+		{name: "reason override", reason: "ImagePullBackOff", want: 12 * time.Hour},
+		{name: "rule", rule: rule, reason: "ImagePullBackOff", want: 3 * time.Hour},
+		{name: "annotation wins over rule", annotation: "10m", rule: rule, reason: "OOMKilled", want: 10 * time.Minute},
+		{name: "invalid annotation keeps rule", annotation: "soon", rule: rule, reason: "OOMKilled", want: 3 * time.Hour},
+		{name: "invalid annotation without rule", annotation: "-5m", reason: "OOMKilled", want: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"}}
+			if tt.annotation != "" {
+				pod.Annotations = map[string]string{cooldownAnnotation: tt.annotation}
+			}
+			if got := c.podCooldown(pod, tt.reason, tt.rule); got != tt.want {
+				t.Errorf("podCooldown() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// correlatedFailureReason is reported for a correlation group whose pods failed for different reasons
+const correlatedFailureReason = "CorrelatedFailure"
+
+// correlationValue returns the pod's CORRELATION_LABEL value, if it has one
+func (c *Controller) correlationValue(labels map[string]string) (string, bool) {
+	if c.cfg.CorrelationLabel == "" {
+		return "", false
+	}
+	v, ok := labels[c.cfg.CorrelationLabel]
+	return v, ok && v != ""
+}
+
+// bufferForCorrelation holds a pod alert for the correlation window, so pods
+// failing together with the same CORRELATION_LABEL value produce one alert
+func (c *Controller) bufferForCorrelation(value string, p pendingAlert) {
+	c.correlationGroupsMu.Lock()
+	if c.draining {
+		c.correlationGroupsMu.Unlock()
+		c.deliver(p)
+		return
+	}
+	defer c.correlationGroupsMu.Unlock()
+	group, started := c.correlationGroups[value]
+	c.correlationGroups[value] = append(group, p)
+	if !started {
+		c.flushes.Add(1)
+		c.correlationTimers[value] = time.AfterFunc(c.cfg.CorrelationWindow, func() {
+			defer c.flushes.Done()
+			c.flushCorrelation(c.ctx, value, c.takeCorrelationGroup(value))
+		})
+	}
+}
+
+// takeCorrelationGroup removes and returns the alerts buffered for value
+func (c *Controller) takeCorrelationGroup(value string) []pendingAlert {
+	c.correlationGroupsMu.Lock()
+	defer c.correlationGroupsMu.Unlock()
+	group := c.correlationGroups[value]
+	delete(c.correlationGroups, value)
+	delete(c.correlationTimers, value)
+	return group
+}
+
+// flushCorrelation sends the alerts buffered for value, folding them into a
+// single alert if there are enough of them
+func (c *Controller) flushCorrelation(ctx context.Context, value string, group []pendingAlert) {
+	if len(group) == 0 {
+		return
+	}
+	if len(group) < c.cfg.CorrelationMinPods {
+		for _, p := range group {
+			c.deliver(p)
+		}
+		return
+	}
+
+	label := c.cfg.CorrelationLabel
+	affected, namespace := affectedPods(group)
+	reasons := make(map[string]bool)
+	severity := SeverityInfo
+	for _, p := range group {
+		reasons[p.alert.Reason] = true
+		if p.alert.Severity.AtLeast(severity) {
+			severity = p.alert.Severity
+		}
+	}
+	reason := correlatedFailureReason
+	if len(reasons) == 1 {
+		reason = group[0].alert.Reason
+	}
+	names := make([]string, 0, len(reasons))
+	for r := range reasons {
+		names = append(names, r)
+	}
+	sort.Strings(names)
+
+	alert := &Alert{
+		Namespace:    namespace,
+		OwnerKind:    "Correlation",
+		OwnerName:    label + "=" + value,
+		Labels:       map[string]string{label: value},
+		Reason:       reason,
+		Severity:     severity,
+		Detail:       fmt.Sprintf("%d pods with %s=%s failed within %v (%s)", len(group), label, value, c.cfg.CorrelationWindow, strings.Join(names, ", ")),
+		AffectedPods: affected,
+	}
+	log.Printf("Folding %d pod alerts with %s=%s into one correlated alert", len(group), label, value)
+	c.auditFold(group, "Correlation:"+label+"="+value)
+	if !c.triggerWorkload(ctx, "Correlation:"+label+"="+value, label+"="+value, c.cfg.AlertCooldown, alert) {
+		// Let the pods alert on their own next time rather than lose them
+		for _, p := range group {
+			c.cacheUndo(p.key, p.res)
+		}
+	}
+}
+
+// affectedPods lists the namespace/name of each buffered pod alert, and returns
+// the namespace they share, if they all share one
+func affectedPods(group []pendingAlert) ([]string, string) {
+	affected := make([]string, 0, len(group))
+	namespace := ""
+	for i, p := range group {
+		affected = append(affected, p.alert.Namespace+"/"+p.alert.PodName)
+		if i == 0 {
+			namespace = p.alert.Namespace
+		} else if p.alert.Namespace != namespace {
+			namespace = ""
+		}
+	}
+	return affected, namespace
+}
+
+// drainBuffered sends the alerts still held for node and correlation windows on
+// shutdown, so they are not lost, and waits for flushes already under way. It
+// returns before the notifiers are closed. Alerts buffered afterwards are sent at once.
+func (c *Controller) drainBuffered() {
+	c.nodeGroupsMu.Lock()
+	c.correlationGroupsMu.Lock()
+	c.draining = true
+	nodes, correlations := c.nodeGroups, c.correlationGroups
+	c.nodeGroups, c.correlationGroups = make(map[string][]pendingAlert), make(map[string][]pendingAlert)
+	for _, timers := range []map[string]*time.Timer{c.nodeTimers, c.correlationTimers} {
+		for key, timer := range timers {
+			if timer.Stop() {
+				c.flushes.Done()
+			}
+			delete(timers, key)
+		}
+	}
+	c.correlationGroupsMu.Unlock()
+	c.nodeGroupsMu.Unlock()
+
+	if len(nodes) == 0 && len(correlations) == 0 {
+		c.flushes.Wait()
+		return
+	}
+	log.Printf("Sending the alerts buffered for %d node and %d correlation groups before stopping", len(nodes), len(correlations))
+
+	// The controller's context is already cancelled; SHUTDOWN_TIMEOUT bounds the sends
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.ctx), c.cfg.ShutdownTimeout)
+	defer cancel()
+	for node, group := range nodes {
+		c.flushNode(ctx, node, withContext(ctx, group))
+	}
+	for value, group := range correlations {
+		c.flushCorrelation(ctx, value, withContext(ctx, group))
+	}
+	c.flushes.Wait()
+}
+
+// withContext moves the buffered alerts onto ctx, keeping each alert's span
+func withContext(ctx context.Context, group []pendingAlert) []pendingAlert {
+	for i := range group {
+		group[i].ctx = trace.ContextWithSpan(ctx, trace.SpanFromContext(group[i].ctx))
+	}
+	return group
+}
@@ -0,0 +1,79 @@
+package monitor
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// daemonSetDegradedReason is reported when a DaemonSet has too few ready pods across its nodes
+const daemonSetDegradedReason = "DaemonSetDegraded"
+
+// watchDaemonSets adds a DaemonSet informer to the controller.
+// A node that never got its pod has no bad pod to observe, so this compares counts instead.
+func (c *Controller) watchDaemonSets(factory informers.SharedInformerFactory) {
+	informer := factory.Apps().V1().DaemonSets().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.checkDaemonSet(obj) },
+		UpdateFunc: func(_, newObj interface{}) { c.checkDaemonSet(newObj) },
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if ds, ok := obj.(*appsv1.DaemonSet); ok {
+				c.workloadRecovered(daemonSetKey(ds))
+			}
+		},
+	})
+	c.daemonSets = append(c.daemonSets, informer)
+	c.auxInformers = append(c.auxInformers, informer)
+}
+
+// recheckDaemonSets re-evaluates every DaemonSet, since one can cross the threshold without changing
+func (c *Controller) recheckDaemonSets() {
+	for _, inf := range c.daemonSets {
+		for _, obj := range inf.GetStore().List() {
+			c.checkDaemonSet(obj)
+		}
+	}
+}
+
+// daemonSetKey is the alert cache key of a DaemonSet
+func daemonSetKey(ds *appsv1.DaemonSet) string {
+	return "DaemonSet:" + string(ds.UID)
+}
+
+// checkDaemonSet alerts once more than DAEMONSET_MAX_MISSING of a DaemonSet's
+// nodes have been without a ready pod for longer than the threshold
+func (c *Controller) checkDaemonSet(obj interface{}) {
+	ds, ok := obj.(*appsv1.DaemonSet)
+	if !ok || !c.addsArmed.Load() {
+		return
+	}
+	key := daemonSetKey(ds)
+
+	desired, ready := ds.Status.DesiredNumberScheduled, ds.Status.NumberReady
+	if int(desired-ready) <= c.cfg.DaemonSetMaxMissing {
+		c.workloadRecovered(key)
+		return
+	}
+
+	since := c.workloadBelowSince(key)
+	if c.clock.Since(since) < c.cfg.DaemonSetDegradedThreshold {
+		return
+	}
+
+	failedSince := since.UTC()
+	alert := &Alert{
+		Namespace:   ds.Namespace,
+		OwnerKind:   "DaemonSet",
+		OwnerName:   ds.Name,
+		Reason:      daemonSetDegradedReason,
+		Severity:    c.severityFor(ds.Namespace, daemonSetDegradedReason),
+		Detail:      fmt.Sprintf("%d/%d pods ready (%d scheduled, %d misscheduled)", ready, desired, ds.Status.CurrentNumberScheduled, ds.Status.NumberMisscheduled),
+		FailedSince: &failedSince,
+	}
+	c.triggerWorkload(c.ctx, key, fmt.Sprintf("daemonset %s/%s", ds.Namespace, ds.Name), c.namespaceCooldown(ds.Namespace), alert)
+}
@@ -0,0 +1,27 @@
+package monitor
+
+import "time"
+
+// debounceFor returns how long a pod must have been bad before an alert for
+// reason is sent, from REASON_DEBOUNCE; 0 alerts at once
+func (c *Controller) debounceFor(reason string) time.Duration {
+	return c.cfg.ReasonDebounce[reason]
+}
+
+// badFor returns how long the pod has been in a bad state. It prefers the time
+// the failing set first saw the pod, which survives reason changes such as
+// ErrImagePull flipping to ImagePullBackOff, and falls back to the state's own
+// estimate; ok is false if neither is known.
+func (c *Controller) badFor(podKey string, state badState) (time.Duration, bool) {
+	c.failingMu.Lock()
+	tracked, ok := c.failing[podKey]
+	c.failingMu.Unlock()
+	switch {
+	case ok:
+		return c.clock.Since(tracked.Since), true
+	case !state.Since.IsZero():
+		return c.clock.Since(state.Since), true
+	default:
+		return 0, false
+	}
+}
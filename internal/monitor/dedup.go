@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"log"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// dedupScopeAnnotation overrides the dedup scope of a single pod
+const dedupScopeAnnotation = "watch-my-pod/dedup-scope"
+
+// Dedup scopes: one cooldown per pod, or one per owning workload and reason
+const (
+	DedupScopePod   = "pod"
+	DedupScopeOwner = "owner"
+)
+
+// validDedupScope reports whether s is a known dedup scope
+func validDedupScope(s string) bool {
+	return s == DedupScopePod || s == DedupScopeOwner
+}
+
+// dedupScopeFor resolves the pod's dedup scope: its annotation, else its namespace's, else DEDUP_SCOPE
+func (c *Controller) dedupScopeFor(pod *corev1.Pod) string {
+	if v, ok := pod.Annotations[dedupScopeAnnotation]; ok {
+		if validDedupScope(v) {
+			return v
+		}
+		log.Printf("WARNING: Ignoring invalid %s annotation %q on pod %s/%s", dedupScopeAnnotation, v, pod.Namespace, pod.Name)
+	}
+	if s, ok := c.cfg.NamespaceDedupScopes[pod.Namespace]; ok {
+		return s
+	}
+	return c.cfg.DedupScope
+}
+
+// dedupKey returns the alert cache key for the pod's alert. With owner scope,
+// replicas of the same workload failing for the same reason share one key;
+// pods without an owner always dedup on their own.
+func (c *Controller) dedupKey(pod *corev1.Pod, reason string) string {
+	podKey := pod.Namespace + "/" + pod.Name
+	if c.dedupScopeFor(pod) != DedupScopeOwner {
+		return podKey
+	}
+	kind, name := podOwner(pod)
+	if name == "" {
+		return podKey
+	}
+	return "Owner:" + pod.Namespace + "/" + kind + "/" + name + "/" + reason
+}
@@ -0,0 +1,142 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// deploymentUnavailableReason is reported when a Deployment stays below its available replica target
+const deploymentUnavailableReason = "DeploymentUnavailable"
+
+// watchDeployments adds a Deployment informer to the controller
+func (c *Controller) watchDeployments(factory informers.SharedInformerFactory) {
+	informer := factory.Apps().V1().Deployments().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.checkDeployment(obj) },
+		UpdateFunc: func(_, newObj interface{}) { c.checkDeployment(newObj) },
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if d, ok := obj.(*appsv1.Deployment); ok {
+				c.workloadRecovered(deploymentKey(d))
+			}
+		},
+	})
+	c.deployments = append(c.deployments, informer)
+	c.auxInformers = append(c.auxInformers, informer)
+}
+
+// recheckDeployments re-evaluates every Deployment, since one can cross the threshold without changing
+func (c *Controller) recheckDeployments() {
+	for _, inf := range c.deployments {
+		for _, obj := range inf.GetStore().List() {
+			c.checkDeployment(obj)
+		}
+	}
+}
+
+// deploymentKey is the alert cache key of a Deployment
+func deploymentKey(d *appsv1.Deployment) string {
+	return "Deployment:" + string(d.UID)
+}
+
+// availableTarget is the number of available replicas below which the Deployment counts as unavailable
+func (c *Controller) availableTarget(d *appsv1.Deployment) int32 {
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+	return int32(math.Ceil(float64(desired) * c.cfg.DeploymentAvailableFraction))
+}
+
+// checkDeployment alerts once a Deployment has had too few available replicas for longer than the threshold
+func (c *Controller) checkDeployment(obj interface{}) {
+	d, ok := obj.(*appsv1.Deployment)
+	if !ok || !c.addsArmed.Load() {
+		return
+	}
+	key := deploymentKey(d)
+
+	target := c.availableTarget(d)
+	if d.Status.AvailableReplicas >= target {
+		c.workloadRecovered(key)
+		return
+	}
+
+	since := c.workloadBelowSince(key)
+	if c.clock.Since(since) < c.cfg.DeploymentUnavailableThreshold {
+		return
+	}
+
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+	failedSince := since.UTC()
+	alert := &Alert{
+		Namespace:   d.Namespace,
+		OwnerKind:   "Deployment",
+		OwnerName:   d.Name,
+		Reason:      deploymentUnavailableReason,
+		Severity:    c.severityFor(d.Namespace, deploymentUnavailableReason),
+		Detail:      fmt.Sprintf("%d/%d replicas available (need %d)", d.Status.AvailableReplicas, desired, target),
+		FailedSince: &failedSince,
+	}
+	c.triggerWorkload(c.ctx, key, fmt.Sprintf("deployment %s/%s", d.Namespace, d.Name), c.namespaceCooldown(d.Namespace), alert)
+}
+
+// workloadBelowSince returns when the workload was first seen below its target, recording now if it wasn't
+func (c *Controller) workloadBelowSince(key string) time.Time {
+	c.workloadMu.Lock()
+	defer c.workloadMu.Unlock()
+	since, ok := c.workloadBelow[key]
+	if !ok {
+		since = c.clock.Now()
+		c.workloadBelow[key] = since
+	}
+	return since
+}
+
+// workloadRecovered forgets a workload that is healthy again, so its next degradation alerts immediately
+func (c *Controller) workloadRecovered(key string) {
+	c.workloadMu.Lock()
+	_, wasBelow := c.workloadBelow[key]
+	delete(c.workloadBelow, key)
+	c.workloadMu.Unlock()
+
+	if wasBelow {
+		c.cacheClear(key)
+	}
+}
+
+// triggerWorkload sends a workload-level alert, deduplicated by key like pod alerts.
+// It returns false only if the alert was due but not delivered.
+func (c *Controller) triggerWorkload(ctx context.Context, key, desc string, cooldown time.Duration, alert *Alert) bool {
+	if w, ok := c.inMaintenance(alert.Namespace); ok {
+		log.Printf("SUPPRESSED ALERT for %s (%s) during maintenance window %s.", desc, alert.Reason, w)
+		return true
+	}
+	res := c.reserveAlert(key, alert.Reason, cooldown)
+	if !res.Won {
+		return true
+	}
+	alert.Kind = res.Kind
+	alert.cacheKey = key
+	alert.occurredAt = res.Entry.At
+
+	log.Printf("TRIGGER_CHECK: %s is in bad state: %s (%s)", desc, alert.Reason, alert.Detail)
+	if !c.notify(ctx, alert) {
+		log.Printf("Alert for %s was not delivered; it will be retried on the next check", desc)
+		c.cacheUndo(key, res)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingNotifier delivers every alert by keeping it
+type recordingNotifier struct {
+	mu     sync.Mutex
+	alerts []*Alert
+}
+
+func (n *recordingNotifier) Name() string { return "recording" }
+
+func (n *recordingNotifier) Notify(_ context.Context, alert *Alert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func (n *recordingNotifier) sent() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.alerts)
+}
+
+func TestTriggerWorkloadAppliesAgentSuppression(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	n := &recordingNotifier{}
+	cfg := testConfig(t)
+	cfg.AgentMaxSuppressFor = 24 * time.Hour
+	c := testController(t, cfg, clock, n)
+
+	newAlert := func() *Alert {
+		return &Alert{Namespace: "shop", OwnerKind: "Deployment", OwnerName: "web", Reason: deploymentUnavailableReason}
+	}
+	const key = "Deployment:uid-web"
+	alert := newAlert()
+	c.triggerWorkload(context.Background(), key, "deployment shop/web", time.Hour, alert)
+	if n.sent() != 1 {
+		t.Fatalf("first alert sent %d times, want 1", n.sent())
+	}
+	c.handleAgentResponse(alert, []byte(`{"summary": "rollout in progress", "suppress_for": "6h"}`))
+
+	clock.Advance(2 * time.Hour)
+	c.triggerWorkload(context.Background(), key, "deployment shop/web", time.Hour, newAlert())
+	if n.sent() != 1 {
+		t.Error("a Deployment alert the agent suppressed was sent again once the cooldown ended")
+	}
+
+	clock.Advance(5 * time.Hour)
+	c.triggerWorkload(context.Background(), key, "deployment shop/web", time.Hour, newAlert())
+	if n.sent() != 2 {
+		t.Errorf("alert sent %d times after the suppression ended, want 2", n.sent())
+	}
+}
@@ -0,0 +1,131 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"reflect"
+)
+
+// redacted replaces secret values in the effective configuration
+const redacted = "REDACTED"
+
+// EffectiveConfig returns the resolved configuration keyed by field name, for display.
+// Fields tagged redact:"secret" are masked, and fields tagged redact:"url" have
+// their credentials and query values masked, since tokens are often embedded there.
+func (cfg *Config) EffectiveConfig() map[string]interface{} {
+	out := make(map[string]interface{})
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := displayValue(v.Field(i))
+		switch field.Tag.Get("redact") {
+		case "secret":
+			value = redactSecret(value)
+		case "url":
+			value = redactURLs(value)
+		}
+		out[field.Name] = value
+	}
+	return out
+}
+
+// displayValue converts values with a String method, like durations and
+// time zones, to their string form, including inside slices, maps and pointers
+func displayValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		return displayValue(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = displayValue(v.Index(i))
+		}
+		return s
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = displayValue(iter.Value())
+		}
+		return m
+	}
+	return v.Interface()
+}
+
+// redactSecret masks a non-empty secret, or every value of a map of secrets
+func redactSecret(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			return redacted
+		}
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = redacted
+		}
+	}
+	return value
+}
+
+// redactURLs masks a URL or a list of URLs
+func redactURLs(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return redactURL(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, s := range v {
+			out[i] = redactURLs(s)
+		}
+		return out
+	}
+	return value
+}
+
+// redactURL masks the user info and query values of a URL, or all of it if it doesn't parse
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Opaque != "" {
+		return redacted
+	}
+	if u.User != nil {
+		u.User = url.User(redacted)
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		for key := range q {
+			q.Set(key, redacted)
+		}
+		u.RawQuery = q.Encode()
+	}
+	u.Fragment = ""
+	return u.String()
+}
+
+// ConfigHandler serves the effective configuration as JSON, secrets redacted
+func ConfigHandler(cfg *Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(cfg.EffectiveConfig()); err != nil {
+			log.Printf("ERROR: Failed to encode /config response: %v", err)
+		}
+	})
+}
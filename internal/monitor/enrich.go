@@ -0,0 +1,259 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// enrichAlert attaches the pod's recent events, log tail and container resources to the alert.
+// Enrichment is best effort: failures are logged and the alert is sent without the data.
+// Fetches are bounded by ENRICHMENT_TIMEOUT; past it the alert keeps what was gathered and is marked partially enriched.
+func (c *Controller) enrichAlert(ctx context.Context, pod *corev1.Pod, container string, alert *Alert) {
+	if c.includeResources(pod, container) {
+		alert.Resources = containerResources(pod, container)
+	}
+	if c.cfg.IncludeImage {
+		alert.Image = containerImage(pod, container)
+	}
+
+	if c.cfg.EnrichmentTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.EnrichmentTimeout)
+		defer cancel()
+	}
+	defer func() {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			alert.PartiallyEnriched = true
+			enrichmentTimeouts.Inc()
+			log.Printf("WARNING: Enrichment of the alert for %s/%s timed out after %v; sending what was gathered",
+				pod.Namespace, pod.Name, c.cfg.EnrichmentTimeout)
+		}
+	}()
+
+	if c.cfg.IncludeEvents && c.caps.events {
+		events, err := c.listPodEvents(ctx, pod)
+		if err != nil {
+			c.logEnrichError(pod, "events", err)
+		} else {
+			alert.Events = toAlertEvents(events)
+		}
+	}
+
+	if c.cfg.IncludeLogs && c.caps.logs && ctx.Err() == nil {
+		var logs strings.Builder
+	containers:
+		for _, ctr := range pod.Spec.Containers {
+			for _, previous := range logInstances(pod, ctr.Name) {
+				tail, err := c.containerLogTail(ctx, pod, ctr.Name, previous)
+				if err != nil {
+					c.logEnrichError(pod, "pods/log", err)
+					if apierrors.IsForbidden(err) || ctx.Err() != nil {
+						break containers
+					}
+					continue
+				}
+				if previous {
+					fmt.Fprintf(&logs, "==> %s (previous) <==\n%s\n", ctr.Name, tail)
+				} else {
+					fmt.Fprintf(&logs, "==> %s <==\n%s\n", ctr.Name, tail)
+				}
+			}
+		}
+		alert.Logs = logs.String()
+	}
+}
+
+// logInstances returns which instances of the container to fetch logs from, as
+// values for Previous. A crash-looping container is in backoff with no output of
+// its own; the crash is in the previous instance's log, so that one comes first.
+func logInstances(pod *corev1.Pod, container string) []bool {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name != container || cs.LastTerminationState.Terminated == nil || cs.RestartCount == 0 {
+			continue
+		}
+		if w := cs.State.Waiting; w != nil && w.Reason == "CrashLoopBackOff" {
+			return []bool{true}
+		}
+		if cs.State.Running != nil {
+			// Between two crashes: both the fresh output and the last crash matter
+			return []bool{true, false}
+		}
+	}
+	return []bool{false}
+}
+
+// containerLogTail returns the last LogTailLines lines of the container's log,
+// or of its previous instance's log. A log that is not there yet, as happens
+// right after a container first fails, is retried LOG_RETRY_ATTEMPTS times.
+func (c *Controller) containerLogTail(ctx context.Context, pod *corev1.Pod, container string, previous bool) (string, error) {
+	for attempt := 0; ; attempt++ {
+		tail, err := c.readLogTail(ctx, pod, container, previous)
+		if err == nil || attempt >= c.cfg.LogRetryAttempts || !logNotReady(err) {
+			return tail, err
+		}
+		if waitRetry(ctx, c.cfg.LogRetryDelay) != nil {
+			return "", err
+		}
+	}
+}
+
+// logNotReady reports whether a GetLogs error means the log isn't available
+// yet, rather than a failure worth giving up on
+func logNotReady(err error) bool {
+	// A deleted pod is NotFound too, so only the kubelet's BadRequest and InternalError replies qualify
+	if !apierrors.IsBadRequest(err) && !apierrors.IsInternalError(err) {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "waiting to start") ||
+		strings.Contains(msg, "not found") ||
+		strings.Contains(msg, "no such file or directory")
+}
+
+// readLogTail makes one GetLogs request for containerLogTail
+func (c *Controller) readLogTail(ctx context.Context, pod *corev1.Pod, container string, previous bool) (string, error) {
+	tailLines := int64(c.cfg.LogTailLines)
+	req := c.Clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &tailLines,
+		Previous:  previous,
+	})
+	stream, err := req.Stream(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	body, err := io.ReadAll(stream)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// logEnrichError reports a failed enrichment read.
+// Forbidden errors are expected under tight RBAC, so they are only logged once per namespace and resource.
+func (c *Controller) logEnrichError(pod *corev1.Pod, resource string, err error) {
+	if apierrors.IsForbidden(err) {
+		if _, seen := c.forbidden.LoadOrStore(pod.Namespace+"/"+resource, struct{}{}); !seen {
+			log.Printf("WARNING: Access to %s in namespace %s is forbidden; alerts from this namespace will be sent without them: %v",
+				resource, pod.Namespace, err)
+		}
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		// Reported once by enrichAlert
+		return
+	}
+	log.Printf("ERROR: Failed to fetch %s for pod %s/%s: %v", resource, pod.Namespace, pod.Name, err)
+}
+
+// toAlertEvents converts events to their alert form, most recent first
+func toAlertEvents(events []corev1.Event) []AlertEvent {
+	sort.Slice(events, func(i, j int) bool {
+		return eventTime(&events[i]).After(eventTime(&events[j]))
+	})
+
+	out := make([]AlertEvent, 0, len(events))
+	for i := range events {
+		ev := &events[i]
+		out = append(out, AlertEvent{
+			Type:     ev.Type,
+			Reason:   ev.Reason,
+			Message:  ev.Message,
+			Count:    ev.Count,
+			LastSeen: eventTime(ev),
+		})
+	}
+	return out
+}
+
+// Modes of INCLUDE_RESOURCES
+const (
+	includeResourcesAlways = "always"
+	includeResourcesMemory = "memory"
+	includeResourcesNever  = "never"
+)
+
+// includeResources decides whether to attach container resources to an alert
+func (c *Controller) includeResources(pod *corev1.Pod, container string) bool {
+	switch c.cfg.IncludeResources {
+	case includeResourcesAlways:
+		return true
+	case includeResourcesMemory:
+		return wasOOMKilled(pod, container)
+	default:
+		return false
+	}
+}
+
+// wasOOMKilled reports whether the container (or any container, if empty) was last killed for running out of memory
+func wasOOMKilled(pod *corev1.Pod, container string) bool {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if container != "" && cs.Name != container {
+			continue
+		}
+		if t := cs.State.Terminated; t != nil && t.Reason == "OOMKilled" {
+			return true
+		}
+		if t := cs.LastTerminationState.Terminated; t != nil && t.Reason == "OOMKilled" {
+			return true
+		}
+	}
+	return false
+}
+
+// containerImage returns the image the pod spec asks for in the named container.
+// Statuses and spec are matched by name since their order can differ.
+func containerImage(pod *corev1.Pod, container string) string {
+	if container == "" {
+		return ""
+	}
+	for _, list := range [][]corev1.Container{pod.Spec.Containers, pod.Spec.InitContainers} {
+		for _, ctr := range list {
+			if ctr.Name == container {
+				return ctr.Image
+			}
+		}
+	}
+	return ""
+}
+
+// containerResources returns the cpu/memory requests and limits of the
+// container from the pod spec, or of every container if container is empty
+func containerResources(pod *corev1.Pod, container string) []ContainerResources {
+	var out []ContainerResources
+	for _, ctr := range pod.Spec.Containers {
+		if container != "" && ctr.Name != container {
+			continue
+		}
+		out = append(out, ContainerResources{
+			Container: ctr.Name,
+			Requests:  resourceStrings(ctr.Resources.Requests),
+			Limits:    resourceStrings(ctr.Resources.Limits),
+		})
+	}
+	return out
+}
+
+// resourceStrings renders the cpu and memory quantities of a resource list
+func resourceStrings(list corev1.ResourceList) map[string]string {
+	out := make(map[string]string)
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if q, ok := list[name]; ok {
+			out[string(name)] = q.String()
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+const (
+	// containerCreatingReason is the waiting reason of a container whose sandbox or volumes are not ready yet
+	containerCreatingReason = "ContainerCreating"
+
+	// containerCreatingTimeoutReason is reported when a container stays in ContainerCreating past the configured timeout
+	containerCreatingTimeoutReason = "ContainerCreatingTimeout"
+)
+
+// volumeEventReasons are the event reasons that explain a pod stuck on its volumes
+var volumeEventReasons = map[string]bool{
+	"FailedMount":        true,
+	"FailedAttachVolume": true,
+}
+
+// podScheduledTime returns when the pod was scheduled, falling back to its creation time
+func podScheduledTime(pod *corev1.Pod) time.Time {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionTrue {
+			return cond.LastTransitionTime.Time
+		}
+	}
+	return pod.CreationTimestamp.Time
+}
+
+// listPodEvents returns the events recorded against the pod
+func (c *Controller) listPodEvents(ctx context.Context, pod *corev1.Pod) ([]corev1.Event, error) {
+	selector := fields.Set{
+		"involvedObject.kind":      "Pod",
+		"involvedObject.name":      pod.Name,
+		"involvedObject.namespace": pod.Namespace,
+		"involvedObject.uid":       string(pod.UID),
+	}.AsSelector().String()
+
+	events, err := c.Clientset.CoreV1().Events(pod.Namespace).List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	return events.Items, nil
+}
+
+// volumeFailureReason looks for the most recent volume mount/attach failure event of the pod
+func (c *Controller) volumeFailureReason(ctx context.Context, pod *corev1.Pod) (string, bool) {
+	if !c.caps.events {
+		return "", false
+	}
+	events, err := c.listPodEvents(ctx, pod)
+	if err != nil {
+		c.logEnrichError(pod, "events", err)
+		return "", false
+	}
+
+	var latest *corev1.Event
+	for i := range events {
+		ev := &events[i]
+		if !volumeEventReasons[ev.Reason] {
+			continue
+		}
+		if latest == nil || eventTime(ev).After(eventTime(latest)) {
+			latest = ev
+		}
+	}
+	if latest == nil {
+		return "", false
+	}
+	return latest.Reason, true
+}
+
+// eventTime returns the most recent time the event was observed
+func eventTime(ev *corev1.Event) time.Time {
+	if !ev.LastTimestamp.IsZero() {
+		return ev.LastTimestamp.Time
+	}
+	if !ev.EventTime.IsZero() {
+		return ev.EventTime.Time
+	}
+	return ev.CreationTimestamp.Time
+}
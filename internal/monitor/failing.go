@@ -0,0 +1,264 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// failingPod is a pod currently in a bad state
+type failingPod struct {
+	// Reason is the bad state the pod entered with
+	Reason string
+	// Since is when the pod was first observed in a bad state
+	Since time.Time
+	// Escalated is set once a chronic failure alert was sent for this episode
+	Escalated bool
+	// DedupKey is the alert cache key of the episode's latest alert, which is
+	// not the pod's own key under DEDUP_SCOPE=owner
+	DedupKey string
+	// HeldBack is the reason and rule that last suppressed the pod's alert,
+	// so the recheck doesn't log and count the same suppression every time
+	HeldBack string
+}
+
+// markFailing records that the pod is in a bad state, keeping the time it first entered it
+func (c *Controller) markFailing(pod *corev1.Pod, reason string) {
+	podKey := pod.Namespace + "/" + pod.Name
+
+	c.failingMu.Lock()
+	defer c.failingMu.Unlock()
+	if _, ok := c.failing[podKey]; ok {
+		return
+	}
+	if !c.failingLimit.admit(len(c.failing)) {
+		return
+	}
+	c.failing[podKey] = failingPod{Reason: reason, Since: c.clock.Now(), DedupKey: c.dedupKey(pod, reason)}
+	failingPods.Set(float64(len(c.failing)))
+	c.stats.observeFailing(len(c.failing))
+}
+
+// setFailingDedupKey records the cache key the pod's latest alert was deduplicated on
+func (c *Controller) setFailingDedupKey(podKey, dedupKey string) {
+	c.failingMu.Lock()
+	defer c.failingMu.Unlock()
+	if state, ok := c.failing[podKey]; ok {
+		state.DedupKey = dedupKey
+		state.HeldBack = ""
+		c.failing[podKey] = state
+	}
+}
+
+// repeatHoldBack records why the pod's alert was suppressed, reporting whether
+// it was already suppressed the same way for the same bad state
+func (c *Controller) repeatHoldBack(podKey, why string) bool {
+	c.failingMu.Lock()
+	defer c.failingMu.Unlock()
+	state, ok := c.failing[podKey]
+	if !ok {
+		return false
+	}
+	if state.HeldBack == why {
+		return true
+	}
+	state.HeldBack = why
+	c.failing[podKey] = state
+	return false
+}
+
+// podCacheKeys returns the alert cache keys holding alerts for the pod
+func (c *Controller) podCacheKeys(podKey string) []string {
+	keys := []string{podKey}
+	c.failingMu.Lock()
+	defer c.failingMu.Unlock()
+	if state, ok := c.failing[podKey]; ok && state.DedupKey != "" && state.DedupKey != podKey {
+		keys = append(keys, state.DedupKey)
+	}
+	return keys
+}
+
+// markRecovered handles a pod leaving its bad state, observing how long it was bad
+func (c *Controller) markRecovered(pod *corev1.Pod) {
+	podKey := pod.Namespace + "/" + pod.Name
+
+	c.failingMu.Lock()
+	state, ok := c.failing[podKey]
+	if ok {
+		delete(c.failing, podKey)
+		failingPods.Set(float64(len(c.failing)))
+	}
+	c.failingMu.Unlock()
+	if !ok {
+		return
+	}
+	c.forgetAudit(podKey)
+
+	duration := c.clock.Since(state.Since)
+	badStateDuration.WithLabelValues(state.Reason).Observe(duration.Seconds())
+	podRecoveries.WithLabelValues(state.Reason).Inc()
+	log.Printf("RESOLVED: Pod %s recovered from %s after %v", podKey, state.Reason, duration.Round(time.Second))
+
+	if c.cfg.NotifyResolved {
+		c.notifyResolved(pod, state)
+	}
+}
+
+// notifyResolved sends a resolved alert for a recovered pod that was alerted on
+func (c *Controller) notifyResolved(pod *corev1.Pod, state failingPod) {
+	podKey := pod.Namespace + "/" + pod.Name
+	cacheKey := state.DedupKey
+	if cacheKey == "" {
+		cacheKey = podKey
+	}
+
+	last, alerted := c.cacheGet(cacheKey)
+	if !alerted {
+		return
+	}
+	if !c.reserveResolved(podKey) {
+		// A flapping pod would otherwise announce every short-lived recovery
+		log.Printf("SUPPRESSED ALERT for %s (resolved). A resolved alert was sent less than RESOLVED_MIN_INTERVAL (%v) ago.",
+			podKey, c.cfg.ResolvedMinInterval)
+		c.stats.suppressed.Add(1)
+		return
+	}
+	dedupDecisions.WithLabelValues("resolved").Inc()
+
+	ownerKind, ownerName := podOwner(pod)
+	since := state.Since.UTC()
+	alert := &Alert{
+		Namespace:   pod.Namespace,
+		PodName:     pod.Name,
+		podUID:      string(pod.UID),
+		NodeName:    pod.Spec.NodeName,
+		OwnerKind:   ownerKind,
+		OwnerName:   ownerName,
+		Labels:      c.identityLabels(pod),
+		Reason:      last.Reason,
+		Severity:    c.podSeverity(pod, last.Reason),
+		Kind:        AlertKindResolved,
+		FailedSince: &since,
+	}
+	c.routeAlert(pod, alert)
+	if !c.notify(c.ctx, alert) {
+		log.Printf("Resolved alert for %s was not delivered", podKey)
+	}
+}
+
+// reserveResolved reports whether a resolved alert may be sent for the pod now,
+// recording the attempt so further ones within RESOLVED_MIN_INTERVAL are suppressed
+func (c *Controller) reserveResolved(podKey string) bool {
+	if c.cfg.ResolvedMinInterval <= 0 {
+		return true
+	}
+	now := c.clock.Now()
+
+	c.failingMu.Lock()
+	defer c.failingMu.Unlock()
+	if at, ok := c.resolvedAt[podKey]; ok && now.Sub(at) < c.cfg.ResolvedMinInterval {
+		return false
+	}
+	c.resolvedAt[podKey] = now
+	return true
+}
+
+// forgetFailing drops a deleted pod from the failing set without counting it as a recovery
+func (c *Controller) forgetFailing(pod *corev1.Pod) {
+	podKey := pod.Namespace + "/" + pod.Name
+
+	c.failingMu.Lock()
+	defer c.failingMu.Unlock()
+	delete(c.resolvedAt, podKey)
+	if _, ok := c.failing[podKey]; ok {
+		delete(c.failing, podKey)
+		failingPods.Set(float64(len(c.failing)))
+	}
+}
+
+// checkChronic counts the pods bad for longer than CHRONIC_FAILURE_AFTER and,
+// if enabled, escalates each of them once per failure episode
+func (c *Controller) checkChronic() {
+	var escalate []string
+	chronic := 0
+
+	c.failingMu.Lock()
+	for podKey, state := range c.failing {
+		if c.clock.Since(state.Since) < c.cfg.ChronicFailureAfter {
+			continue
+		}
+		chronic++
+		if c.cfg.EscalateChronic && !state.Escalated {
+			escalate = append(escalate, podKey)
+		}
+	}
+	c.failingMu.Unlock()
+	chronicFailures.Set(float64(chronic))
+
+	if !c.addsArmed.Load() {
+		return
+	}
+	for _, podKey := range escalate {
+		c.escalateChronic(podKey)
+	}
+}
+
+// escalateChronic sends a chronic failure alert for a pod that has been bad too long
+func (c *Controller) escalateChronic(podKey string) {
+	pod, ok := c.getPod(podKey)
+	if !ok {
+		return
+	}
+	c.failingMu.Lock()
+	state, ok := c.failing[podKey]
+	c.failingMu.Unlock()
+	if !ok {
+		return
+	}
+	isBad, current := c.checkPodBadState(pod)
+	if !isBad {
+		return
+	}
+	rule, ok := c.passesGates(pod, current)
+	if !ok {
+		return
+	}
+
+	ownerKind, ownerName := podOwner(pod)
+	since := state.Since.UTC()
+	alert := &Alert{
+		Namespace:   pod.Namespace,
+		PodName:     pod.Name,
+		podUID:      string(pod.UID),
+		NodeName:    pod.Spec.NodeName,
+		OwnerKind:   ownerKind,
+		OwnerName:   ownerName,
+		Labels:      c.identityLabels(pod),
+		Reason:      state.Reason,
+		Severity:    c.podSeverity(pod, state.Reason),
+		Kind:        AlertKindChronic,
+		Detail:      fmt.Sprintf("bad for %v", c.clock.Since(state.Since).Round(time.Minute)),
+		FailedSince: &since,
+	}
+	if rule != nil && rule.severity != "" {
+		if _, annotated := annotatedSeverity(pod); !annotated {
+			alert.Severity = rule.severity
+		}
+	}
+	log.Printf("TRIGGER_CHECK: Pod %s has been failing with %s since %v", podKey, state.Reason, since)
+	c.routeAlert(pod, alert)
+	if !c.notify(c.ctx, alert) {
+		// Escalated stays unset, so the next chronic check tries again
+		log.Printf("Chronic failure alert for %s was not delivered", podKey)
+		return
+	}
+
+	c.failingMu.Lock()
+	if current, ok := c.failing[podKey]; ok && current.Since.Equal(state.Since) {
+		current.Escalated = true
+		c.failing[podKey] = current
+	}
+	c.failingMu.Unlock()
+}
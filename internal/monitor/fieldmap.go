@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// alertFieldNames returns the JSON names of the Alert's top-level fields
+func alertFieldNames() map[string]bool {
+	names := make(map[string]bool)
+	t := reflect.TypeOf(Alert{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// validateFieldMap checks that a field mapping renames known fields to distinct names
+func validateFieldMap(mapping map[string]string) error {
+	known := alertFieldNames()
+	targets := make(map[string]string)
+	for from, to := range mapping {
+		if !known[from] {
+			return fmt.Errorf("unknown alert field %q", from)
+		}
+		if to == "" {
+			return fmt.Errorf("empty name for alert field %q", from)
+		}
+		if other, ok := targets[to]; ok {
+			return fmt.Errorf("alert fields %q and %q are both mapped to %q", other, from, to)
+		}
+		targets[to] = from
+	}
+	for to, from := range targets {
+		if known[to] {
+			if _, renamed := mapping[to]; !renamed {
+				return fmt.Errorf("alert field %q is mapped to %q, which is already a field name", from, to)
+			}
+		}
+	}
+	return nil
+}
+
+// marshalAlert encodes the alert as JSON, renaming top-level fields by mapping
+func marshalAlert(alert *Alert, mapping map[string]string) ([]byte, error) {
+	data, err := json.Marshal(alert)
+	if err != nil || len(mapping) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	renamed := make(map[string]json.RawMessage, len(fields))
+	for name, value := range fields {
+		if to, ok := mapping[name]; ok {
+			name = to
+		}
+		renamed[name] = value
+	}
+	return json.Marshal(renamed)
+}
@@ -0,0 +1,250 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/adityapore231/Watch-my-pod/internal/agentpb"
+)
+
+// GRPCNotifier sends alerts to the agent's SummarizePod RPC over one long-lived connection.
+// It is the gRPC alternative to AgentNotifier, with the same timeout, retry and concurrency settings.
+type GRPCNotifier struct {
+	conn   *grpc.ClientConn
+	client agentpb.AgentServiceClient
+
+	timeout    time.Duration
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	deadline   time.Duration
+
+	// headers are sent as metadata on every call, like AGENT_HEADERS on HTTP requests
+	headers map[string]string
+	token   *tokenSource
+
+	slots *agentSlots
+
+	// onResponse, when set, receives every response re-encoded as the HTTP agent's JSON
+	onResponse func(alert *Alert, body []byte)
+}
+
+// NewGRPCNotifier creates a notifier for the agent at AGENT_GRPC_ADDR.
+// The connection is made lazily and re-established by gRPC as needed.
+func NewGRPCNotifier(cfg *Config) (*GRPCNotifier, error) {
+	creds := insecure.NewCredentials()
+	if cfg.AgentGRPCTLS {
+		tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+		if cfg.AgentGRPCCAFile != "" {
+			pem, err := os.ReadFile(cfg.AgentGRPCCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read AGENT_GRPC_CA_FILE: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in AGENT_GRPC_CA_FILE %s", cfg.AgentGRPCCAFile)
+			}
+			tlsCfg.RootCAs = pool
+		}
+		creds = credentials.NewTLS(tlsCfg)
+	}
+
+	conn, err := grpc.NewClient(cfg.AgentGRPCAddr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client for %s: %w", cfg.AgentGRPCAddr, err)
+	}
+	return &GRPCNotifier{
+		conn:       conn,
+		client:     agentpb.NewAgentServiceClient(conn),
+		timeout:    cfg.AgentTimeout,
+		maxRetries: cfg.AgentMaxRetries,
+		backoff:    cfg.AgentRetryBackoff,
+		maxBackoff: cfg.AgentRetryMaxBackoff,
+		deadline:   cfg.AgentRetryDeadline,
+		headers:    cfg.AgentHeaders,
+		token:      newTokenSource(cfg),
+		slots:      newAgentSlots(cfg),
+	}, nil
+}
+
+// Name implements Notifier
+func (n *GRPCNotifier) Name() string {
+	return "agent"
+}
+
+// SetClock implements clockUser; AGENT_MAX_QUEUE_AGE is measured with it
+func (n *GRPCNotifier) SetClock(clock Clock) {
+	n.slots.clock = clock
+}
+
+// SetResponseHandler implements responseCapturer
+func (n *GRPCNotifier) SetResponseHandler(fn func(alert *Alert, body []byte)) {
+	n.onResponse = fn
+}
+
+// Close closes the connection to the agent
+func (n *GRPCNotifier) Close() error {
+	return n.conn.Close()
+}
+
+// Notify implements Notifier, retrying unavailable or overloaded agents with exponential backoff
+func (n *GRPCNotifier) Notify(ctx context.Context, alert *Alert) error {
+	log.Printf("Triggering analysis for pod: %s/%s (Reason: %s)", alert.Namespace, alert.PodName, alert.Reason)
+
+	req := alertToProto(alert)
+	ctx, cancel := withRetryDeadline(ctx, n.deadline)
+	defer cancel()
+
+	queued := n.slots.clock.Now()
+	backoff := capBackoff(n.backoff, n.maxBackoff)
+	for attempt := 0; ; attempt++ {
+		resp, retry, err := n.attempt(ctx, req, queued)
+		if err == nil {
+			n.handleResponse(alert, resp)
+			return nil
+		}
+		if !retry || attempt >= n.maxRetries {
+			return fmt.Errorf("agent SummarizePod failed for pod %s: %w", alert.PodName, err)
+		}
+
+		log.Printf("WARNING: Agent attempt %d/%d for pod %s/%s failed, retrying in %v: %v",
+			attempt+1, n.maxRetries+1, alert.Namespace, alert.PodName, backoff, err)
+		if werr := waitRetry(ctx, backoff); werr != nil {
+			return fmt.Errorf("gave up retrying agent for pod %s: %w (last error: %v)", alert.PodName, werr, err)
+		}
+		backoff = capBackoff(backoff*2, n.maxBackoff)
+	}
+}
+
+// attempt makes one call, bounded by the agent timeout and the caller's deadline.
+// It reports whether a failure is worth retrying.
+func (n *GRPCNotifier) attempt(ctx context.Context, req *agentpb.SummarizePodRequest, queued time.Time) (*agentpb.SummarizePodResponse, bool, error) {
+	// The slot wait is bounded by the queue age and the retry deadline, not the agent timeout
+	if err := n.slots.acquire(ctx, queued); err != nil {
+		return nil, !errors.Is(err, errQueueAgeExceeded), fmt.Errorf("gave up waiting for an agent slot: %w", err)
+	}
+	defer n.slots.release()
+
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	md := metadata.New(nil)
+	for name, value := range n.headers {
+		md.Set(strings.ToLower(name), value)
+	}
+	if n.token != nil {
+		if token := n.token.Token(); token != "" {
+			md.Set("authorization", "Bearer "+token)
+		}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	resp, err := n.client.SummarizePod(ctx, req)
+	return resp, retryableGRPC(err), err
+}
+
+// metadataCarrier lets the trace propagator write into outgoing gRPC metadata
+type metadataCarrier metadata.MD
+
+func (m metadataCarrier) Get(key string) string {
+	if values := metadata.MD(m).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (m metadataCarrier) Set(key, value string) { metadata.MD(m).Set(key, value) }
+
+func (m metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// handleResponse hands the response to the response handler in the HTTP agent's JSON form
+func (n *GRPCNotifier) handleResponse(alert *Alert, resp *agentpb.SummarizePodResponse) {
+	log.Printf("Successfully triggered analysis for %s/%s over gRPC", alert.Namespace, alert.PodName)
+	if n.onResponse == nil {
+		return
+	}
+	body, err := json.Marshal(AgentResponse{Summary: resp.GetSummary(), SuppressFor: resp.GetSuppressFor()})
+	if err != nil {
+		log.Printf("WARNING: Failed to encode agent response for pod %s/%s: %v", alert.Namespace, alert.PodName, err)
+		return
+	}
+	n.onResponse(alert, body)
+}
+
+// retryableGRPC reports whether a failed call may succeed if repeated
+func retryableGRPC(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// alertToProto maps an alert onto the SummarizePod request
+func alertToProto(alert *Alert) *agentpb.SummarizePodRequest {
+	req := &agentpb.SummarizePodRequest{
+		Namespace:         alert.Namespace,
+		PodName:           alert.PodName,
+		NodeName:          alert.NodeName,
+		OwnerKind:         alert.OwnerKind,
+		OwnerName:         alert.OwnerName,
+		Labels:            alert.Labels,
+		Reason:            alert.Reason,
+		Severity:          string(alert.Severity),
+		Kind:              string(alert.Kind),
+		Detail:            alert.Detail,
+		Container:         alert.Container,
+		Image:             alert.Image,
+		AffectedPods:      alert.AffectedPods,
+		Test:              alert.Test,
+		Logs:              alert.Logs,
+		Truncated:         alert.Truncated,
+		Environment:       alert.Environment,
+		StaticLabels:      alert.StaticLabels,
+		PartiallyEnriched: alert.PartiallyEnriched,
+	}
+	if alert.FailedSince != nil {
+		req.FailedSince = timestamppb.New(*alert.FailedSince)
+	}
+	for _, r := range alert.Resources {
+		req.Resources = append(req.Resources, &agentpb.ContainerResources{
+			Container: r.Container,
+			Requests:  r.Requests,
+			Limits:    r.Limits,
+		})
+	}
+	for _, ev := range alert.Events {
+		req.Events = append(req.Events, &agentpb.Event{
+			Type:     ev.Type,
+			Reason:   ev.Reason,
+			Message:  ev.Message,
+			Count:    ev.Count,
+			LastSeen: timestamppb.New(ev.LastSeen),
+		})
+	}
+	return req
+}
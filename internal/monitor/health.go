@@ -0,0 +1,21 @@
+package monitor
+
+import "net/http"
+
+// HealthHandler answers liveness probes; it only fails if the process can't serve at all
+func HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+}
+
+// ReadyHandler answers readiness probes, succeeding once the informer caches have synced
+func (c *Controller) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.synced.Load() {
+			http.Error(w, "informer caches not synced", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+}
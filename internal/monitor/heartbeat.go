@@ -0,0 +1,24 @@
+package monitor
+
+import "log"
+
+// heartbeat logs a one-line summary of the monitor's state, so a quiet but
+// healthy monitor can be told apart from a hung one
+func (c *Controller) heartbeat() {
+	cached := 0
+	err := c.alertCache.Range(c.ctx, func(string, AlertCacheEntry) bool {
+		cached++
+		return true
+	})
+	if err != nil {
+		log.Printf("WARNING: Failed to count alert cache entries: %v", err)
+	}
+
+	c.failingMu.Lock()
+	failing := len(c.failing)
+	c.failingMu.Unlock()
+
+	log.Printf("HEARTBEAT: watching %d pods, %d alerts sent since last heartbeat, %d cached alerts, %d failing pods",
+		len(c.listPods()), c.alertsSent.Swap(0), cached, failing)
+	heartbeatTimestamp.Set(float64(c.clock.Now().Unix()))
+}
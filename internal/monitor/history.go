@@ -0,0 +1,132 @@
+package monitor
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// alertRecord is an alert as reported by the /alerts endpoint
+type alertRecord struct {
+	Alert         *Alert          `json:"alert"`
+	SentAt        time.Time       `json:"sent_at"`
+	AgentResponse json.RawMessage `json:"agent_response,omitempty"`
+}
+
+// historyKey is the /alerts entry of an alert: namespace/name for a pod, and
+// e.g. Deployment:namespace/name or Node:name for an alert with no pod
+func (a *Alert) historyKey() string {
+	switch {
+	case a.PodName != "" || a.OwnerKind == "":
+		return a.Namespace + "/" + a.PodName
+	case a.OwnerKind == "Node" || a.OwnerKind == "Correlation":
+		// Their namespace is only set when every affected pod shares it
+		return a.OwnerKind + ":" + a.OwnerName
+	default:
+		return a.OwnerKind + ":" + a.Namespace + "/" + a.OwnerName
+	}
+}
+
+// recordSent stores a delivered alert as the latest one for its pod or workload
+func (c *Controller) recordSent(alert *Alert) {
+	c.historyMu.Lock()
+	c.history[alert.historyKey()] = &alertRecord{Alert: alert, SentAt: c.clock.Now(), AgentResponse: json.RawMessage(alert.agentResponse)}
+	c.historyMu.Unlock()
+}
+
+// handleAgentResponse applies the agent's suppression request and, if enabled, records and samples the response
+func (c *Controller) handleAgentResponse(alert *Alert, body []byte) {
+	if c.sampler != nil {
+		c.sampler.maybeRecord(alert, body)
+	}
+	if c.cfg.AgentMaxSuppressFor > 0 {
+		c.applyAgentSuppression(alert, body)
+	}
+	if c.cfg.CaptureAgentResponse {
+		c.recordAgentResponse(alert, body)
+	}
+}
+
+// applyAgentSuppression extends the alert's cache entry by the agent's suppress_for, capped at AGENT_MAX_SUPPRESS_FOR.
+// A missing or malformed value leaves the normal cooldown in place.
+func (c *Controller) applyAgentSuppression(alert *Alert, body []byte) {
+	name := alert.historyKey()
+
+	var resp AgentResponse
+	if err := json.Unmarshal(body, &resp); err != nil || resp.SuppressFor == "" {
+		return
+	}
+	d, err := time.ParseDuration(resp.SuppressFor)
+	if err != nil || d <= 0 {
+		log.Printf("WARNING: Ignoring invalid suppress_for %q from the agent for %s", resp.SuppressFor, name)
+		return
+	}
+	if d > c.cfg.AgentMaxSuppressFor {
+		d = c.cfg.AgentMaxSuppressFor
+	}
+
+	key := alert.cacheKey
+	if key == "" {
+		key = name
+	}
+	entry, ok := c.cacheGet(key)
+	if !ok {
+		return
+	}
+	until := entry.At.Add(d)
+	if !until.After(entry.Until) {
+		return
+	}
+	c.cacheRecord(key, entry.Reason, until.Sub(c.clock.Now()))
+	log.Printf("Agent asked to suppress %s for %v", name, d)
+}
+
+// recordAgentResponse validates the agent's response and attaches it to the alert's /alerts entry
+func (c *Controller) recordAgentResponse(alert *Alert, body []byte) {
+	key := alert.historyKey()
+
+	var resp AgentResponse
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Summary == "" {
+		agentInvalidResponses.Inc()
+		log.Printf("WARNING: Agent returned an invalid response for %s (expected JSON with a summary): %.200q", key, body)
+		return
+	}
+	log.Printf("Agent summary for %s: %s", key, resp.Summary)
+
+	c.historyMu.Lock()
+	defer c.historyMu.Unlock()
+	if rec, ok := c.history[key]; ok && rec.Alert == alert {
+		rec.AgentResponse = json.RawMessage(body)
+		return
+	}
+	// The agent answered before the fan-out finished; recordSent picks it up
+	alert.agentResponse = body
+}
+
+// AlertsHandler serves the latest alert sent for each pod or workload, newest first.
+// The optional ?pod= query restricts the result to one historyKey, e.g. namespace/name.
+func (c *Controller) AlertsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pod := r.URL.Query().Get("pod")
+
+		c.historyMu.RLock()
+		records := make([]alertRecord, 0, len(c.history))
+		for key, rec := range c.history {
+			if pod == "" || pod == key {
+				records = append(records, *rec)
+			}
+		}
+		c.historyMu.RUnlock()
+
+		sort.Slice(records, func(i, j int) bool {
+			return records[i].SentAt.After(records[j].SentAt)
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(records); err != nil {
+			log.Printf("ERROR: Failed to encode /alerts response: %v", err)
+		}
+	})
+}
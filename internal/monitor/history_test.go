@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testConfig is the default configuration
+func testConfig(t *testing.T) *Config {
+	t.Helper()
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	return cfg
+}
+
+// testController builds a controller on a fake clientset
+func testController(t *testing.T, cfg *Config, clock Clock, notifiers ...Notifier) *Controller {
+	t.Helper()
+	return NewController(fake.NewSimpleClientset(), cfg, notifiers, WithClock(clock))
+}
+
+func TestAlertHistoryKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		alert Alert
+		want  string
+	}{
+		{name: "pod", alert: Alert{Namespace: "shop", PodName: "web-1", OwnerKind: "Deployment", OwnerName: "web"}, want: "shop/web-1"},
+		{name: "deployment", alert: Alert{Namespace: "shop", OwnerKind: "Deployment", OwnerName: "web"}, want: "Deployment:shop/web"},
+		{name: "daemonset", alert: Alert{Namespace: "kube-system", OwnerKind: "DaemonSet", OwnerName: "cni"}, want: "DaemonSet:kube-system/cni"},
+		{name: "node", alert: Alert{Namespace: "shop", OwnerKind: "Node", OwnerName: "node-a"}, want: "Node:node-a"},
+		{name: "correlation", alert: Alert{OwnerKind: "Correlation", OwnerName: "app=web"}, want: "Correlation:app=web"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.alert.historyKey(); got != tt.want {
+				t.Errorf("historyKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertsHandlerKeepsWorkloadAlertsApart(t *testing.T) {
+	c := testController(t, testConfig(t), NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)))
+	c.recordSent(&Alert{Namespace: "shop", OwnerKind: "Deployment", OwnerName: "web", Reason: deploymentUnavailableReason})
+	c.recordSent(&Alert{Namespace: "shop", OwnerKind: "Deployment", OwnerName: "api", Reason: deploymentUnavailableReason})
+
+	for query, want := range map[string]int{"": 2, "?pod=Deployment:shop/api": 1} {
+		w := httptest.NewRecorder()
+		c.AlertsHandler().ServeHTTP(w, httptest.NewRequest("GET", "/alerts"+query, nil))
+		var records []alertRecord
+		if err := json.Unmarshal(w.Body.Bytes(), &records); err != nil {
+			t.Fatalf("/alerts%s returned invalid JSON: %v", query, err)
+		}
+		if len(records) != want {
+			t.Errorf("/alerts%s returned %d alerts, want %d", query, len(records), want)
+		}
+	}
+}
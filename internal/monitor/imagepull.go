@@ -0,0 +1,67 @@
+package monitor
+
+import "sync"
+
+// repeatedImagePullReason replaces the image pull reason once IMAGE_PULL_MIN_FAILURES is reached
+const repeatedImagePullReason = "RepeatedImagePullFailure"
+
+// imagePullTracker counts failed image pull attempts per container
+type imagePullTracker struct {
+	mu   sync.Mutex
+	pods map[string]map[string]*pullAttempts
+}
+
+// pullAttempts is the pull history of one container
+type pullAttempts struct {
+	// last is the waiting reason last observed
+	last  string
+	count int
+}
+
+// observe records the container's image pull waiting reason and returns the attempts so far.
+// The kubelet flips between ErrImagePull on each failed attempt and ImagePullBackOff while
+// it waits, so each move into ErrImagePull is one attempt; rechecks of the same status don't count.
+func (t *imagePullTracker) observe(podKey, container, reason string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pods == nil {
+		t.pods = make(map[string]map[string]*pullAttempts)
+	}
+	containers, ok := t.pods[podKey]
+	if !ok {
+		containers = make(map[string]*pullAttempts)
+		t.pods[podKey] = containers
+	}
+	pa, ok := containers[container]
+	if !ok {
+		pa = &pullAttempts{}
+		containers[container] = pa
+	}
+	// The first observation counts even in back-off, when the monitor started after the first attempt
+	if reason != pa.last && (reason == "ErrImagePull" || pa.last == "") {
+		pa.count++
+	}
+	pa.last = reason
+	return pa.count
+}
+
+// reset drops the container's attempts once it is no longer failing to pull
+func (t *imagePullTracker) reset(podKey, container string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	containers, ok := t.pods[podKey]
+	if !ok {
+		return
+	}
+	delete(containers, container)
+	if len(containers) == 0 {
+		delete(t.pods, podKey)
+	}
+}
+
+// forget drops the attempts tracked for a deleted pod
+func (t *imagePullTracker) forget(podKey string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.pods, podKey)
+}
@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRepeatedImagePullFailure(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.ImagePullMinFailures = 2
+	c := testController(t, cfg, NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)))
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "registry.example.com/web:1"}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	steps := []struct {
+		waiting string
+		bad     bool
+	}{
+		{waiting: "ErrImagePull"},
+		{waiting: "ImagePullBackOff"},
+		{waiting: "ErrImagePull", bad: true},
+		{waiting: "ImagePullBackOff", bad: true},
+	}
+	for i, step := range steps {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "app",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: step.waiting, Message: "not found"}},
+		}}
+		bad, state := c.checkPodBadState(pod)
+		if bad != step.bad {
+			t.Fatalf("step %d (%s): bad = %v, want %v", i, step.waiting, bad, step.bad)
+		}
+		if bad && (state.Reason != repeatedImagePullReason || state.Detail != "2 failed pulls: not found") {
+			t.Errorf("step %d (%s): state = %q, %q, want %q, %q", i, step.waiting, state.Reason, state.Detail, repeatedImagePullReason, "2 failed pulls: not found")
+		}
+	}
+}
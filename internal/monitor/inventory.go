@@ -0,0 +1,92 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// inventory is the snapshot of failing pods written to INVENTORY_FILE
+type inventory struct {
+	WrittenAt time.Time       `json:"written_at"`
+	Pods      []inventoryItem `json:"pods"`
+}
+
+// inventoryItem is one failing pod in the inventory
+type inventoryItem struct {
+	Namespace  string    `json:"namespace"`
+	PodName    string    `json:"pod_name"`
+	OwnerKind  string    `json:"owner_kind,omitempty"`
+	OwnerName  string    `json:"owner_name,omitempty"`
+	Reason     string    `json:"reason"`
+	BadSince   time.Time `json:"bad_since"`
+	BadSeconds int64     `json:"bad_seconds"`
+}
+
+// writeInventory writes the currently failing pods to INVENTORY_FILE.
+// The file is replaced atomically so readers never see a partial snapshot.
+func (c *Controller) writeInventory() {
+	now := c.clock.Now()
+	inv := inventory{WrittenAt: now.UTC(), Pods: []inventoryItem{}}
+
+	c.failingMu.Lock()
+	for podKey, state := range c.failing {
+		namespace, name, _ := strings.Cut(podKey, "/")
+		inv.Pods = append(inv.Pods, inventoryItem{
+			Namespace:  namespace,
+			PodName:    name,
+			Reason:     state.Reason,
+			BadSince:   state.Since.UTC(),
+			BadSeconds: int64(now.Sub(state.Since).Seconds()),
+		})
+	}
+	c.failingMu.Unlock()
+
+	for i := range inv.Pods {
+		item := &inv.Pods[i]
+		if pod, ok := c.getPod(item.Namespace + "/" + item.PodName); ok {
+			item.OwnerKind, item.OwnerName = podOwner(pod)
+		}
+	}
+	sort.Slice(inv.Pods, func(i, j int) bool {
+		if inv.Pods[i].Namespace != inv.Pods[j].Namespace {
+			return inv.Pods[i].Namespace < inv.Pods[j].Namespace
+		}
+		return inv.Pods[i].PodName < inv.Pods[j].PodName
+	})
+
+	if err := writeFileAtomic(c.cfg.InventoryFile, inv); err != nil {
+		log.Printf("ERROR: Failed to write the failing pod inventory: %v", err)
+	}
+}
+
+// writeFileAtomic writes v as indented JSON to a temporary file next to path, then renames it over path
+func writeFileAtomic(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", path, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	// CreateTemp makes the file private; the snapshot is meant to be read by others
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -1,23 +1,83 @@
 package monitor
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
 )
 
+// KubeconfigSecret locates a kubeconfig stored in a Secret of the local cluster
+type KubeconfigSecret struct {
+	Namespace string
+	Name      string
+	Key       string
+}
+
 // NewClientset creates and returns a new Kubernetes clientset.
-// It searches for a config file in the following priority:
+// If secret is set, the kubeconfig is read from that Secret using the
+// in-cluster service account. Otherwise it searches for a config file
+// in the following priority:
 // 1. ./configs/kubeconfig
 // 2. KUBECONFIG environment variable
 // 3. ~/.kube/config
 // 4. In-cluster service account
-func NewClientset() (*kubernetes.Clientset, error) {
+func NewClientset(secret *KubeconfigSecret) (*kubernetes.Clientset, error) {
+	config, err := RESTConfig(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the clientset
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return clientset, nil
+}
+
+// RESTConfig finds the configuration of the watched cluster, as described for NewClientset
+func RESTConfig(secret *KubeconfigSecret) (*rest.Config, error) {
+	if secret != nil {
+		return secretConfig(secret)
+	}
+	return localConfig()
+}
+
+// secretConfig builds a rest.Config from a kubeconfig stored in a Secret
+func secretConfig(secret *KubeconfigSecret) (*rest.Config, error) {
+	log.Printf("Using kubeconfig from secret %s/%s (key %q)", secret.Namespace, secret.Name, secret.Key)
+
+	inCluster, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("reading a kubeconfig secret requires in-cluster access: %w", err)
+	}
+	local, err := kubernetes.NewForConfig(inCluster)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := local.CoreV1().Secrets(secret.Namespace).Get(context.Background(), secret.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+	data, ok := s.Data[secret.Key]
+	if !ok {
+		return nil, fmt.Errorf("kubeconfig secret %s/%s has no key %q", secret.Namespace, secret.Name, secret.Key)
+	}
+	return clientcmd.RESTConfigFromKubeConfig(data)
+}
+
+// localConfig finds a kubeconfig file on disk, falling back to the in-cluster config
+func localConfig() (*rest.Config, error) {
 	var config *rest.Config
 	var err error
 	var kubeconfig string
@@ -62,11 +122,5 @@ func NewClientset() (*kubernetes.Clientset, error) {
 		}
 	}
 
-	// Create the clientset
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, err
-	}
-
-	return clientset, nil
+	return config, nil
 }
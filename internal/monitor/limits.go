@@ -0,0 +1,35 @@
+package monitor
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+// trackingLimit caps the size of an in-memory set, so a cluster-wide failure
+// cannot grow the monitor without bound. Entries already in the set are still
+// updated; only new ones are refused.
+type trackingLimit struct {
+	// set names the capped set in logs and metrics
+	set string
+	// max is the cap; 0 disables it
+	max int
+
+	// full is set while the set is at its cap, so the warning is logged once per episode
+	full atomic.Bool
+}
+
+// admit reports whether a new entry may be added to a set holding size entries.
+// The first refusal of an episode is logged and every refusal is counted.
+func (l *trackingLimit) admit(size int) bool {
+	if l.max <= 0 || size < l.max {
+		if l.full.CompareAndSwap(true, false) {
+			log.Printf("Tracking %s again: %d entries, below the cap of %d", l.set, size, l.max)
+		}
+		return true
+	}
+	trackingRejections.WithLabelValues(l.set).Inc()
+	if l.full.CompareAndSwap(false, true) {
+		log.Printf("WARNING: The %s reached its cap of %d entries, new entries are not tracked until it shrinks", l.set, l.max)
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	// Embed the zone database so MAINTENANCE_TIMEZONE works in minimal images
+	_ "time/tzdata"
+)
+
+// weekdays maps day abbreviations to time.Weekday
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// MaintenanceWindow is a weekly time range during which alerts are not sent
+type MaintenanceWindow struct {
+	// Namespace is a namespace glob; empty applies the window to every alert
+	Namespace string
+
+	// Days are the days the window starts on
+	Days [7]bool
+
+	// Start and End are minutes after midnight; End before Start runs past midnight
+	Start, End int
+
+	spec string
+}
+
+// String describes the window for logs
+func (w MaintenanceWindow) String() string {
+	return w.spec
+}
+
+// ParseMaintenanceWindow parses "[namespace@]days HH:MM-HH:MM", where days is
+// "*", a day ("Sat") or a range of days ("Mon-Fri")
+func ParseMaintenanceWindow(spec string) (MaintenanceWindow, error) {
+	w := MaintenanceWindow{spec: spec}
+	rest := strings.TrimSpace(spec)
+	if ns, after, ok := strings.Cut(rest, "@"); ok {
+		if _, err := path.Match(ns, ""); err != nil {
+			return w, fmt.Errorf("invalid namespace pattern %q: %w", ns, err)
+		}
+		w.Namespace, rest = strings.TrimSpace(ns), after
+	}
+
+	days, hours, ok := strings.Cut(strings.TrimSpace(rest), " ")
+	if !ok {
+		return w, fmt.Errorf("expected [namespace@]days HH:MM-HH:MM")
+	}
+	if err := w.parseDays(days); err != nil {
+		return w, err
+	}
+
+	start, end, ok := strings.Cut(strings.TrimSpace(hours), "-")
+	if !ok {
+		return w, fmt.Errorf("invalid time range %q: expected HH:MM-HH:MM", hours)
+	}
+	var err error
+	if w.Start, err = parseClock(start); err != nil {
+		return w, err
+	}
+	if w.End, err = parseClock(end); err != nil {
+		return w, err
+	}
+	if w.Start == w.End {
+		return w, fmt.Errorf("invalid time range %q: start and end are equal", hours)
+	}
+	return w, nil
+}
+
+// parseDays sets the days the window starts on
+func (w *MaintenanceWindow) parseDays(days string) error {
+	if days == "*" {
+		for i := range w.Days {
+			w.Days[i] = true
+		}
+		return nil
+	}
+	first, last, isRange := strings.Cut(strings.ToLower(days), "-")
+	from, ok := weekdays[first]
+	if !ok {
+		return fmt.Errorf("invalid day %q", first)
+	}
+	to := from
+	if isRange {
+		if to, ok = weekdays[last]; !ok {
+			return fmt.Errorf("invalid day %q", last)
+		}
+	}
+	for d := from; ; d = (d + 1) % 7 {
+		w.Days[d] = true
+		if d == to {
+			return nil
+		}
+	}
+}
+
+// parseClock parses HH:MM into minutes after midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// active reports whether the window covers t for the namespace
+func (w MaintenanceWindow) active(t time.Time, namespace string) bool {
+	if w.Namespace != "" && !globMatch(w.Namespace, namespace) {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if w.Start < w.End {
+		return w.Days[t.Weekday()] && minute >= w.Start && minute < w.End
+	}
+	// The window runs past midnight: it covers the evening of a start day and the morning after
+	yesterday := (t.Weekday() + 6) % 7
+	return (w.Days[t.Weekday()] && minute >= w.Start) || (w.Days[yesterday] && minute < w.End)
+}
+
+// inMaintenance returns the maintenance window covering the namespace now, if any
+func (c *Controller) inMaintenance(namespace string) (MaintenanceWindow, bool) {
+	now := c.clock.Now().In(c.cfg.MaintenanceLocation)
+	for _, w := range c.cfg.MaintenanceWindows {
+		if w.active(now, namespace) {
+			return w, true
+		}
+	}
+	return MaintenanceWindow{}, false
+}
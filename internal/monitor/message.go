@@ -0,0 +1,46 @@
+package monitor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// messageVariable matches a {name} placeholder in MESSAGE_PREFIX or MESSAGE_SUFFIX
+var messageVariable = regexp.MustCompile(`\{[^{}]*\}`)
+
+// MessageFormat brands the human-readable text a notifier produces, e.g. an
+// SNS subject, with the expanded MESSAGE_PREFIX and MESSAGE_SUFFIX.
+type MessageFormat struct {
+	Prefix string
+	Suffix string
+}
+
+// NewMessageFormat expands {cluster} and {environment} in the configured prefix and suffix
+func NewMessageFormat(cfg *Config) MessageFormat {
+	r := strings.NewReplacer("{cluster}", cfg.ClusterName, "{environment}", cfg.Environment)
+	return MessageFormat{Prefix: r.Replace(cfg.MessagePrefix), Suffix: r.Replace(cfg.MessageSuffix)}
+}
+
+// Apply wraps text in the prefix and suffix, truncating text rather than
+// them when the result must fit in limit bytes (0 for no limit)
+func (f MessageFormat) Apply(text string, limit int) string {
+	if limit <= 0 || len(f.Prefix)+len(text)+len(f.Suffix) <= limit {
+		return f.Prefix + text + f.Suffix
+	}
+	if room := limit - len(f.Prefix) - len(f.Suffix); room > len(truncatedMarker) {
+		return f.Prefix + truncate(text, room) + f.Suffix
+	}
+	// The prefix and suffix alone are too long; cut the whole message instead
+	return truncate(f.Prefix+text+f.Suffix, limit)
+}
+
+// validateMessageTemplate rejects placeholders other than {cluster} and {environment}
+func validateMessageTemplate(name, tmpl string) error {
+	for _, v := range messageVariable.FindAllString(tmpl, -1) {
+		if v != "{cluster}" && v != "{environment}" {
+			return fmt.Errorf("%s has unknown variable %s: expected {cluster} or {environment}", name, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package monitor
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsRegistry holds every collector the monitor exposes on /metrics
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	// agentCallsInFlight is the number of requests currently being sent to the agent
+	agentCallsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "watchmypod_agent_calls_in_flight",
+		Help: "Number of agent requests currently in flight.",
+	})
+
+	// agentQueueDropped counts alerts dropped after waiting longer than AGENT_MAX_QUEUE_AGE for an agent slot
+	agentQueueDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "watchmypod_agent_queue_dropped_total",
+		Help: "Number of agent calls dropped because they waited longer than AGENT_MAX_QUEUE_AGE for a free slot.",
+	})
+
+	// agentInvalidResponses counts captured agent responses that did not have the expected shape
+	agentInvalidResponses = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "watchmypod_agent_invalid_responses_total",
+		Help: "Number of agent responses that were not valid JSON with a summary.",
+	})
+
+	// payloadTruncations counts alert fields cut to fit MAX_PAYLOAD_BYTES
+	payloadTruncations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "watchmypod_payload_truncations_total",
+		Help: "Number of alert payload fields truncated to fit MAX_PAYLOAD_BYTES, by field.",
+	}, []string{"field"})
+
+	// enrichmentTimeouts counts alerts whose enrichment was cut short by ENRICHMENT_TIMEOUT
+	enrichmentTimeouts = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "watchmypod_enrichment_timeouts_total",
+		Help: "Number of alerts sent partially enriched because fetching events or logs exceeded ENRICHMENT_TIMEOUT.",
+	})
+
+	// failingPods is the number of pods currently in a bad state
+	failingPods = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "watchmypod_failing_pods",
+		Help: "Number of pods currently in a bad state.",
+	})
+
+	// chronicFailures is the number of pods bad for longer than CHRONIC_FAILURE_AFTER
+	chronicFailures = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "watchmypod_chronic_failures",
+		Help: "Number of pods that have been continuously bad for longer than CHRONIC_FAILURE_AFTER.",
+	})
+
+	// badStateDuration observes how long pods stayed bad before recovering
+	badStateDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "watchmypod_bad_state_duration_seconds",
+		Help:    "Time pods spent in a bad state before recovering, by the reason they entered it with.",
+		Buckets: []float64{30, 60, 300, 900, 1800, 3600, 7200, 21600, 86400},
+	}, []string{"reason"})
+
+	// trackingRejections counts new entries refused because MAX_FAILING_PODS or MAX_ALERT_CACHE_ENTRIES was reached
+	trackingRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "watchmypod_tracking_rejections_total",
+		Help: "Number of new entries not tracked because their set was at its cap, by set (failing_pods, alert_cache).",
+	}, []string{"set"})
+
+	// podEvents counts the pod add, update and delete events the informers delivered
+	podEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "watchmypod_pod_events_total",
+		Help: "Number of pod events observed, by event (add, update, delete).",
+	}, []string{"event"})
+
+	// podObservations counts pod add and update events by whether the pod was healthy or bad
+	podObservations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "watchmypod_pod_observations_total",
+		Help: "Number of pod states evaluated on add and update events, by state (healthy, bad).",
+	}, []string{"state"})
+
+	// podRecoveries counts pods that left a bad state
+	podRecoveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "watchmypod_pod_recoveries_total",
+		Help: "Number of pods that recovered from a bad state, by the reason they entered it with.",
+	}, []string{"reason"})
+
+	// alertsDelivered counts alerts delivered by at least one notifier
+	alertsDelivered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "watchmypod_alerts_delivered_total",
+		Help: "Number of alerts delivered by at least one notifier, by kind (first, repeat, reason-changed, chronic, resolved).",
+	}, []string{"kind"})
+
+	// alertsTriggered counts pod alerts that passed deduplication, with the trace ID of a sampled alert span as exemplar
+	alertsTriggered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "watchmypod_alerts_triggered_total",
+		Help: "Number of pod alerts that passed deduplication and were handed on for delivery, by kind.",
+	}, []string{"kind"})
+
+	// dedupDecisions counts the deduplication outcome of each pod bad-state observation and recovery
+	dedupDecisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "watchmypod_dedup_decisions_total",
+		Help: "Number of deduplication decisions for pod alerts, by decision (sent, suppressed, reason_changed, resolved).",
+	}, []string{"decision"})
+
+	// notifierAttempts counts alerts handed to each notifier, by outcome
+	notifierAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "watchmypod_notifier_attempts_total",
+		Help: "Number of alerts handed to each notifier, by notifier and result (success or failure).",
+	}, []string{"notifier", "result"})
+
+	// notifierDuration observes how long each notifier took to deliver an alert, retries included
+	notifierDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "watchmypod_notifier_duration_seconds",
+		Help:    "Time each notifier took to handle an alert, including retries.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+	}, []string{"notifier"})
+
+	// heartbeatTimestamp is when the monitor last logged a heartbeat
+	heartbeatTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "watchmypod_heartbeat_timestamp",
+		Help: "Unix time of the monitor's last heartbeat.",
+	})
+)
+
+// RegisterMetrics registers the monitor's collectors, adding an environment
+// label to every series when ENVIRONMENT is set, and the STATIC_LABELS.
+// Call it once at startup.
+func RegisterMetrics(environment string, staticLabels map[string]string) {
+	labels := prometheus.Labels{}
+	for k, v := range staticLabels {
+		labels[k] = v
+	}
+	if environment != "" {
+		labels["environment"] = environment
+	}
+	var reg prometheus.Registerer = metricsRegistry
+	if len(labels) > 0 {
+		reg = prometheus.WrapRegistererWith(labels, reg)
+	}
+	reg.MustRegister(
+		agentCallsInFlight,
+		agentQueueDropped,
+		agentInvalidResponses,
+		payloadTruncations,
+		enrichmentTimeouts,
+		failingPods,
+		chronicFailures,
+		badStateDuration,
+		trackingRejections,
+		podEvents,
+		podObservations,
+		podRecoveries,
+		heartbeatTimestamp,
+		alertsDelivered,
+		alertsTriggered,
+		dedupDecisions,
+		notifierAttempts,
+		notifierDuration,
+	)
+}
+
+// MetricsHandler returns the HTTP handler that serves the monitor's metrics
+func MetricsHandler() http.Handler {
+	// Exemplars are only exposed in the OpenMetrics format, which scrapers ask for explicitly
+	return promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
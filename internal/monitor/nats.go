@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSNotifier publishes alerts as JSON to NATS subjects of the form <prefix>.<namespace>.<reason>
+type NATSNotifier struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATSNotifier connects to the NATS server at url.
+// The connection retries in the background, so a NATS outage at startup is not fatal.
+func NewNATSNotifier(url, subjectPrefix string) (*NATSNotifier, error) {
+	conn, err := nats.Connect(url,
+		nats.Name("watch-my-pod"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(2*time.Second),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				log.Printf("WARNING: Disconnected from NATS: %v", err)
+			}
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			log.Println("Reconnected to NATS at", nc.ConnectedUrl())
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS at %s: %w", url, err)
+	}
+	return &NATSNotifier{conn: conn, prefix: strings.TrimSuffix(subjectPrefix, ".")}, nil
+}
+
+// Name implements Notifier
+func (n *NATSNotifier) Name() string {
+	return "nats"
+}
+
+// Notify implements Notifier
+func (n *NATSNotifier) Notify(ctx context.Context, alert *Alert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON for pod %s: %w", alert.PodName, err)
+	}
+	subject := n.prefix + "." + natsToken(alert.Namespace) + "." + natsToken(alert.Reason)
+	if err := n.conn.Publish(subject, payload); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", subject, err)
+	}
+	return nil
+}
+
+// Close flushes pending messages and closes the connection
+func (n *NATSNotifier) Close() error {
+	err := n.conn.FlushTimeout(5 * time.Second)
+	n.conn.Close()
+	return err
+}
+
+// natsToken makes s safe to use as a single NATS subject token
+func natsToken(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
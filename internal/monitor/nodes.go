@@ -0,0 +1,94 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// nodeNotReadyReason is reported when many pods fail together on a NotReady node
+const nodeNotReadyReason = "NodeNotReady"
+
+// bufferForNode holds a pod alert for the correlation window, so pods failing
+// together on a dead node produce one node-level alert
+func (c *Controller) bufferForNode(node string, p pendingAlert) {
+	c.nodeGroupsMu.Lock()
+	if c.draining {
+		c.nodeGroupsMu.Unlock()
+		c.deliver(p)
+		return
+	}
+	defer c.nodeGroupsMu.Unlock()
+	group, started := c.nodeGroups[node]
+	c.nodeGroups[node] = append(group, p)
+	if !started {
+		c.flushes.Add(1)
+		c.nodeTimers[node] = time.AfterFunc(c.cfg.NodeCorrelationWindow, func() {
+			defer c.flushes.Done()
+			c.flushNode(c.ctx, node, c.takeNodeGroup(node))
+		})
+	}
+}
+
+// takeNodeGroup removes and returns the alerts buffered for node
+func (c *Controller) takeNodeGroup(node string) []pendingAlert {
+	c.nodeGroupsMu.Lock()
+	defer c.nodeGroupsMu.Unlock()
+	group := c.nodeGroups[node]
+	delete(c.nodeGroups, node)
+	delete(c.nodeTimers, node)
+	return group
+}
+
+// flushNode sends the alerts buffered for node, folding them into a single
+// node alert if there are enough of them and the node is NotReady
+func (c *Controller) flushNode(ctx context.Context, node string, group []pendingAlert) {
+	if len(group) == 0 {
+		return
+	}
+	if len(group) < c.cfg.NodeCorrelationMinPods || !c.nodeNotReady(ctx, node) {
+		for _, p := range group {
+			c.deliver(p)
+		}
+		return
+	}
+
+	affected, namespace := affectedPods(group)
+	alert := &Alert{
+		Namespace:    namespace,
+		NodeName:     node,
+		OwnerKind:    "Node",
+		OwnerName:    node,
+		Reason:       nodeNotReadyReason,
+		Severity:     c.severityFor(namespace, nodeNotReadyReason),
+		Detail:       fmt.Sprintf("node %s is NotReady, %d pods affected", node, len(group)),
+		AffectedPods: affected,
+	}
+	log.Printf("Folding %d pod alerts on NotReady node %s into one node alert", len(group), node)
+	c.auditFold(group, "Node:"+node)
+	if !c.triggerWorkload(ctx, "Node:"+node, "node "+node, c.cfg.AlertCooldown, alert) {
+		// Let the pods alert on their own next time rather than lose them
+		for _, p := range group {
+			c.cacheUndo(p.key, p.res)
+		}
+	}
+}
+
+// nodeNotReady reports whether the node's Ready condition is not True
+func (c *Controller) nodeNotReady(ctx context.Context, name string) bool {
+	node, err := c.Clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		log.Printf("WARNING: Failed to get node %s for correlation: %v", name, err)
+		return false
+	}
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status != corev1.ConditionTrue
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFlushNodeAppliesCriticalNamespaceFloor(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.NodeCorrelationMinPods = 2
+	cfg.ReasonSeverities = nil
+	cfg.DefaultSeverity = SeverityInfo
+	cfg.CriticalNamespaces = []string{"payments"}
+	cfg.CriticalNamespaceSeverity = SeverityCritical
+	n := &recordingNotifier{}
+	c := testController(t, cfg, NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)), n)
+	c.Clientset = fake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionUnknown},
+		}},
+	})
+
+	ctx := context.Background()
+	var group []pendingAlert
+	for _, name := range []string{"api-1", "api-2"} {
+		group = append(group, pendingAlert{
+			ctx:   ctx,
+			key:   "payments/" + name,
+			alert: &Alert{Namespace: "payments", PodName: name, NodeName: "node-a", Reason: "CrashLoopBackOff"},
+		})
+	}
+	c.flushNode(ctx, "node-a", group)
+
+	if len(n.alerts) != 1 {
+		t.Fatalf("sent %d alerts, want one node alert", len(n.alerts))
+	}
+	if got := n.alerts[0]; got.Reason != nodeNotReadyReason || got.Severity != SeverityCritical {
+		t.Errorf("node alert = %s with severity %q, want %s with severity %q", got.Reason, got.Severity, nodeNotReadyReason, SeverityCritical)
+	}
+}
@@ -0,0 +1,228 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Notifier delivers alerts to a downstream sink
+type Notifier interface {
+	// Name identifies the notifier in logs
+	Name() string
+
+	// Notify sends the alert, returning an error if it was not delivered
+	Notify(ctx context.Context, alert *Alert) error
+}
+
+// defaultNotifierTimeouts bound each notifier type's handling of one alert when
+// NOTIFIER_TIMEOUTS doesn't. The agent has none: AGENT_TIMEOUT and
+// AGENT_RETRY_DEADLINE already bound its attempts and retries.
+var defaultNotifierTimeouts = map[string]time.Duration{
+	"nats":   5 * time.Second,
+	"sns":    10 * time.Second,
+	"pubsub": 10 * time.Second,
+	"syslog": 10 * time.Second,
+}
+
+// notifierTimeout returns how long the named notifier may take for one alert; 0 is unbounded
+func (c *Controller) notifierTimeout(name string) time.Duration {
+	if d, ok := c.cfg.NotifierTimeouts[name]; ok {
+		return d
+	}
+	return defaultNotifierTimeouts[name]
+}
+
+// NewNotifiers builds the notifiers enabled by the configuration
+func NewNotifiers(cfg *Config) ([]Notifier, error) {
+	agent, err := newAgentNotifiers(cfg)
+	if err != nil {
+		return nil, err
+	}
+	notifiers := []Notifier{agent}
+
+	if cfg.NATSURL != "" {
+		n, err := NewNATSNotifier(cfg.NATSURL, cfg.NATSSubjectPrefix)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+
+	if cfg.SNSTopicARN != "" {
+		n, err := NewSNSNotifier(context.Background(), cfg.SNSTopicARN, NewMessageFormat(cfg))
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+
+	if cfg.PubSubProject != "" || cfg.PubSubTopic != "" {
+		n, err := NewPubSubNotifier(context.Background(), cfg.PubSubProject, cfg.PubSubTopic)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+
+	if cfg.SyslogAddr != "" {
+		n, err := NewSyslogNotifier(cfg)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+
+	for name := range cfg.NotifierMinSeverities {
+		if !hasNotifier(notifiers, name) {
+			log.Printf("WARNING: NOTIFIER_MIN_SEVERITY names notifier %q, which is not enabled", name)
+		}
+	}
+	for name := range cfg.NotifierTimeouts {
+		if !hasNotifier(notifiers, name) {
+			log.Printf("WARNING: NOTIFIER_TIMEOUTS names notifier %q, which is not enabled", name)
+		}
+	}
+	for _, route := range cfg.NotifierRoutes {
+		for _, name := range route.Notifiers {
+			if !hasNotifier(notifiers, name) {
+				log.Printf("WARNING: NOTIFIER_ROUTES route %s names notifier %q, which is not enabled", route, name)
+			}
+		}
+	}
+
+	return notifiers, nil
+}
+
+// hasNotifier reports whether a notifier with the given name is enabled
+func hasNotifier(notifiers []Notifier, name string) bool {
+	for _, n := range notifiers {
+		if n.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CloseNotifiers flushes and closes every notifier that holds a connection
+func CloseNotifiers(notifiers []Notifier) {
+	for _, n := range notifiers {
+		if closer, ok := n.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				log.Printf("ERROR: Failed to close notifier %s: %v", n.Name(), err)
+			}
+		}
+	}
+}
+
+// notify fans the alert out to every notifier concurrently.
+// Notifiers whose minimum severity the alert doesn't meet are skipped.
+// Each notifier fails independently; errors are logged. It reports
+// whether at least one notifier delivered the alert, or true if no
+// notifier wanted it.
+func (c *Controller) notify(ctx context.Context, alert *Alert) bool {
+	alert.Environment = c.cfg.Environment
+	if len(c.cfg.StaticLabels) > 0 {
+		alert.StaticLabels = c.cfg.StaticLabels
+	}
+	if alert.occurredAt.IsZero() {
+		alert.occurredAt = c.clock.Now()
+	}
+
+	var wg sync.WaitGroup
+	var delivered atomic.Bool
+	var resultsMu sync.Mutex
+	var received, failed []string
+	eligible := 0
+	for _, n := range c.notifiers {
+		if alert.routes != nil && !alert.routes[n.Name()] {
+			continue
+		}
+		if min, ok := c.cfg.NotifierMinSeverities[n.Name()]; ok && !alert.Severity.AtLeast(min) {
+			continue
+		}
+		eligible++
+		wg.Add(1)
+		go func(n Notifier) {
+			defer wg.Done()
+			// Each notifier gets its own deadline, so a slow one can't eat into the others'
+			nctx := ctx
+			if timeout := c.notifierTimeout(n.Name()); timeout > 0 {
+				var cancel context.CancelFunc
+				nctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			start := time.Now()
+			err := n.Notify(nctx, alert)
+			notifierDuration.WithLabelValues(n.Name()).Observe(time.Since(start).Seconds())
+			if err != nil {
+				notifierAttempts.WithLabelValues(n.Name(), "failure").Inc()
+				log.Printf("ERROR: Notifier %s failed for pod %s/%s: %v", n.Name(), alert.Namespace, alert.PodName, err)
+				resultsMu.Lock()
+				failed = append(failed, n.Name())
+				resultsMu.Unlock()
+				return
+			}
+			notifierAttempts.WithLabelValues(n.Name(), "success").Inc()
+			delivered.Store(true)
+			resultsMu.Lock()
+			received = append(received, n.Name())
+			resultsMu.Unlock()
+		}(n)
+	}
+	wg.Wait()
+	switch {
+	case eligible == 0:
+		c.auditAlert(alert, auditDropped, nil, nil)
+	case delivered.Load():
+		c.auditAlert(alert, auditSent, received, failed)
+	default:
+		c.auditAlert(alert, auditNotDelivered, nil, failed)
+	}
+	if delivered.Load() {
+		c.recordSent(alert)
+		c.alertsSent.Add(1)
+		c.stats.sent.Add(1)
+		if alert.Kind == AlertKindResolved {
+			c.stats.resolved.Add(1)
+		}
+		alertsDelivered.WithLabelValues(string(alert.Kind)).Inc()
+	} else if eligible > 0 {
+		c.stats.failed.Add(1)
+	}
+	if eligible == 0 {
+		log.Printf("No routed notifier takes %s alerts; dropping alert for pod %s/%s", alert.Severity, alert.Namespace, alert.PodName)
+		return true
+	}
+	return delivered.Load()
+}
+
+// TestNotifiers sends a synthetic alert through each notifier and writes
+// the per-notifier result and latency to w. It returns false if any failed.
+func TestNotifiers(ctx context.Context, notifiers []Notifier, w io.Writer) bool {
+	alert := &Alert{
+		Namespace: "watch-my-pod-test",
+		PodName:   "test-notification",
+		Reason:    "TestNotification",
+		Severity:  SeverityInfo,
+		Test:      true,
+	}
+
+	ok := true
+	for _, n := range notifiers {
+		start := time.Now()
+		err := n.Notify(ctx, alert)
+		latency := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			ok = false
+			fmt.Fprintf(w, "FAIL %-12s %8v  %v\n", n.Name(), latency, err)
+			continue
+		}
+		fmt.Fprintf(w, "OK   %-12s %8v\n", n.Name(), latency)
+	}
+	return ok
+}
@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// onceListPageSize bounds each page of the --once pod list
+const onceListPageSize = 500
+
+// CheckOnce lists the watched pods straight from the API server and writes
+// every bad one to w, returning how many there were. It reads from the API
+// rather than an informer store, so an unsynced cache can never yield a false
+// all-clear; any list error is returned instead of a partial result.
+func (c *Controller) CheckOnce(ctx context.Context, w io.Writer) (int, error) {
+	namespaces := c.cfg.WatchNamespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	checked, bad := 0, 0
+	for _, ns := range namespaces {
+		err := c.eachPod(ctx, ns, func(pod *corev1.Pod) {
+			checked++
+			isBad, state := c.checkPodBadState(pod)
+			if !isBad {
+				return
+			}
+			bad++
+			if state.Container != "" {
+				fmt.Fprintf(w, "BAD  %s/%s  %s (container %s)\n", pod.Namespace, pod.Name, state.Reason, state.Container)
+				return
+			}
+			fmt.Fprintf(w, "BAD  %s/%s  %s\n", pod.Namespace, pod.Name, state.Reason)
+		})
+		if err != nil {
+			return bad, err
+		}
+	}
+	fmt.Fprintf(w, "SUMMARY: checked %d pods, %d bad\n", checked, bad)
+	return bad, nil
+}
+
+// eachPod calls fn for every pod in namespace, listing page by page
+func (c *Controller) eachPod(ctx context.Context, namespace string, fn func(pod *corev1.Pod)) error {
+	opts := metav1.ListOptions{Limit: onceListPageSize}
+	for {
+		list, err := c.Clientset.CoreV1().Pods(namespace).List(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list pods in namespace %q: %w", namespace, err)
+		}
+		for i := range list.Items {
+			fn(&list.Items[i])
+		}
+		if list.Continue == "" {
+			return nil
+		}
+		opts.Continue = list.Continue
+	}
+}
@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+// Option customizes a Controller
+type Option func(*Controller)
+
+// PodEvaluator is custom bad-state logic. It reports whether the pod is bad and, if so, the alert reason.
+type PodEvaluator func(pod *corev1.Pod) (bool, string)
+
+// WithClock replaces the wall clock used for cooldowns, ages and timeouts,
+// including the alert cache's if it takes a clock
+func WithClock(clock Clock) Option {
+	return func(c *Controller) {
+		c.clock = clock
+	}
+}
+
+// WithAlertCache replaces the in-memory alert cache, e.g. with a RedisAlertCache shared by replicas
+func WithAlertCache(cache AlertCache) Option {
+	return func(c *Controller) {
+		c.alertCache = cache
+	}
+}
+
+// WithDynamicClient provides the client used to watch PodAlertRule resources
+func WithDynamicClient(client dynamic.Interface) Option {
+	return func(c *Controller) {
+		c.dynamic = client
+	}
+}
+
+// WithResponseSampler writes a sample of agent request/response pairs to the sampler's sink
+func WithResponseSampler(sampler *ResponseSampler) Option {
+	return func(c *Controller) {
+		c.sampler = sampler
+	}
+}
+
+// WithAuditLog records every alerting decision in the audit log
+func WithAuditLog(audit *AuditLog) Option {
+	return func(c *Controller) {
+		c.audit = audit
+	}
+}
+
+// WithPodEvaluator adds custom bad-state logic, consulted in order after the
+// built-in checks find nothing wrong with a pod
+func WithPodEvaluator(eval PodEvaluator) Option {
+	return func(c *Controller) {
+		c.evaluators = append(c.evaluators, eval)
+	}
+}
+
+// WithTracerProvider traces each alert with the provider instead of the global one.
+// Sampled alert spans also become exemplars on watchmypod_alerts_triggered_total.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Controller) {
+		c.tracer = tp.Tracer(tracerName)
+	}
+}
@@ -0,0 +1,44 @@
+package monitor
+
+import (
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// podOwner resolves the workload that manages the pod.
+// Pods of a Deployment are owned by a ReplicaSet named "<deployment>-<pod-template-hash>",
+// so that is resolved to the Deployment without an extra API call.
+// It returns empty strings for bare pods.
+func podOwner(pod *corev1.Pod) (kind, name string) {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller == nil || !*ref.Controller {
+			continue
+		}
+		if ref.Kind == "ReplicaSet" {
+			if hash, ok := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok {
+				if deployment, found := strings.CutSuffix(ref.Name, "-"+hash); found {
+					return "Deployment", deployment
+				}
+			}
+		}
+		return ref.Kind, ref.Name
+	}
+	return "", ""
+}
+
+// identityLabels copies the pod labels matching IDENTITY_LABELS, e.g. the app.kubernetes.io/* recommended labels
+func (c *Controller) identityLabels(pod *corev1.Pod) map[string]string {
+	var out map[string]string
+	for key, value := range pod.Labels {
+		if !matchesAny(c.cfg.IdentityLabels, key) {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[key] = value
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package monitor
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+	"unicode/utf8"
+)
+
+// truncatedMarker marks text that was cut to fit the payload cap
+const truncatedMarker = "...(truncated)"
+
+// fitPayload trims the alert until its JSON form fits in MaxPayloadBytes.
+// The lowest-priority fields go first: logs, then events, then the detail
+// message. Core fields are never touched, so the result may still exceed
+// the cap if they alone are too large.
+func (c *Controller) fitPayload(alert *Alert) {
+	limit := c.cfg.MaxPayloadBytes
+	if limit <= 0 {
+		return
+	}
+	podKey := alert.Namespace + "/" + alert.PodName
+	initial := payloadSize(alert)
+	if initial <= limit {
+		return
+	}
+
+	// Logs: keep the end, where the crash is
+	if alert.Logs != "" {
+		alert.markTruncated("logs")
+		for alert.Logs != "" && payloadSize(alert) > limit {
+			over := payloadSize(alert) - limit
+			alert.Logs = cutLogHead(alert.Logs, over+len(truncatedMarker))
+		}
+	}
+
+	// Events: drop the oldest first (they are sorted newest first)
+	if len(alert.Events) > 0 && payloadSize(alert) > limit {
+		alert.markTruncated("events")
+		for len(alert.Events) > 0 && payloadSize(alert) > limit {
+			alert.Events = alert.Events[:len(alert.Events)-1]
+		}
+	}
+
+	// Detail: keep the beginning
+	if alert.Detail != "" && payloadSize(alert) > limit {
+		alert.markTruncated("detail")
+		for payloadSize(alert) > limit {
+			keep := len(alert.Detail) - (payloadSize(alert) - limit)
+			if keep <= len(truncatedMarker) {
+				alert.Detail = truncatedMarker
+				break
+			}
+			alert.Detail = truncate(alert.Detail, keep)
+		}
+	}
+
+	for _, field := range alert.Truncated {
+		payloadTruncations.WithLabelValues(field).Inc()
+	}
+	log.Printf("WARNING: Alert payload for %s was %d bytes, over MAX_PAYLOAD_BYTES=%d; truncated %s to %d bytes",
+		podKey, initial, limit, strings.Join(alert.Truncated, ", "), payloadSize(alert))
+}
+
+// payloadSize returns the size of the alert's JSON form
+func payloadSize(alert *Alert) int {
+	b, err := json.Marshal(alert)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
+
+// markTruncated records that a field of the alert was cut
+func (a *Alert) markTruncated(field string) {
+	a.Truncated = append(a.Truncated, field)
+}
+
+// cutLogHead removes at least n bytes from the start of the logs, prefixing the truncation marker
+func cutLogHead(logs string, n int) string {
+	logs = strings.TrimPrefix(logs, truncatedMarker+"\n")
+	if n >= len(logs) {
+		return ""
+	}
+	cut := n
+	// Don't split a multi-byte character
+	for cut < len(logs) && !utf8.RuneStart(logs[cut]) {
+		cut++
+	}
+	return truncatedMarker + "\n" + logs[cut:]
+}
@@ -0,0 +1,137 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// podsFileNotifier writes the alerts of a --pods-file run instead of sending them
+type podsFileNotifier struct {
+	w     io.Writer
+	count int
+}
+
+// Name implements Notifier
+func (n *podsFileNotifier) Name() string {
+	return "pods-file"
+}
+
+// Notify implements Notifier
+func (n *podsFileNotifier) Notify(_ context.Context, alert *Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	n.count++
+	fmt.Fprintf(n.w, "ALERT %s\n", data)
+	return nil
+}
+
+// CheckPodsFile runs detection and the alert pipeline over the pods in path,
+// a JSON or YAML Pod, PodList or List such as `kubectl get pods -o json`
+// writes, without connecting to a cluster. Each bad pod and each alert that
+// would have been sent is written to w; the logs say why a bad pod did not
+// alert. It returns how many pods were bad.
+func CheckPodsFile(ctx context.Context, cfg *Config, path string, w io.Writer) (int, error) {
+	pods, err := readPodsFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	// Alerts must come out before the run ends, so nothing is held for correlation,
+	// and every alert is written whichever real notifiers it would have gone to
+	fileCfg := *cfg
+	fileCfg.NodeCorrelation = false
+	fileCfg.CorrelationLabel = ""
+	fileCfg.NotifierRoutes = nil
+	fileCfg.NotifierMinSeverities = nil
+	// A snapshot has no events or logs to enrich with
+	fileCfg.IncludeEvents = false
+	fileCfg.IncludeLogs = false
+
+	clientset := fake.NewSimpleClientset()
+	clientset.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "pods"}, {Name: "pods/log"}, {Name: "events"}},
+	}}
+	notifier := &podsFileNotifier{w: w}
+	c := NewController(clientset, &fileCfg, []Notifier{notifier})
+	c.ctx = ctx
+
+	bad := 0
+	for _, pod := range pods {
+		isBad, state := c.checkPodBadState(pod)
+		if !isBad {
+			fmt.Fprintf(w, "OK   %s/%s\n", pod.Namespace, pod.Name)
+			continue
+		}
+		bad++
+		if state.Container != "" {
+			fmt.Fprintf(w, "BAD  %s/%s  %s (container %s)\n", pod.Namespace, pod.Name, state.Reason, state.Container)
+		} else {
+			fmt.Fprintf(w, "BAD  %s/%s  %s\n", pod.Namespace, pod.Name, state.Reason)
+		}
+		c.markFailing(pod, state.Reason)
+		c.checkAndTrigger(pod, state)
+	}
+	fmt.Fprintf(w, "SUMMARY: checked %d pods, %d bad, %d alerts\n", len(pods), bad, notifier.count)
+	return bad, nil
+}
+
+// readPodsFile decodes every pod in path, which may hold several YAML documents
+func readPodsFile(path string) ([]*corev1.Pod, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pods file: %w", err)
+	}
+	defer f.Close()
+
+	var pods []*corev1.Pod
+	dec := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var doc json.RawMessage
+		if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+			return pods, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid pods file %s: %w", path, err)
+		}
+		if len(doc) == 0 || string(doc) == "null" {
+			continue
+		}
+
+		var meta struct {
+			Kind  string            `json:"kind"`
+			Items []json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(doc, &meta); err != nil {
+			return nil, fmt.Errorf("invalid pods file %s: %w", path, err)
+		}
+		items := []json.RawMessage{doc}
+		switch meta.Kind {
+		case "Pod":
+		case "PodList", "List":
+			items = meta.Items
+		default:
+			return nil, fmt.Errorf("invalid pods file %s: expected a Pod, PodList or List, got kind %q", path, meta.Kind)
+		}
+		for _, item := range items {
+			pod := &corev1.Pod{}
+			if err := json.Unmarshal(item, pod); err != nil {
+				return nil, fmt.Errorf("invalid pod in %s: %w", path, err)
+			}
+			if pod.Kind != "" && pod.Kind != "Pod" {
+				return nil, fmt.Errorf("invalid pods file %s: list item %s/%s is a %s, not a Pod", path, pod.Namespace, pod.Name, pod.Kind)
+			}
+			pods = append(pods, pod)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package monitor
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// livenessProbeFailingReason is reported when a pod's liveness probe keeps failing
+	livenessProbeFailingReason = "LivenessProbeFailing"
+
+	// unhealthyEventReason is the kubelet event reason of a failed probe
+	unhealthyEventReason = "Unhealthy"
+
+	// failedSandboxEventReason is the kubelet event reason of a pod sandbox that could not be created
+	failedSandboxEventReason = "FailedCreatePodSandBox"
+
+	// sandboxCreateFailedReason is reported for a pod whose sandbox could not be created, e.g. by the CNI plugin
+	sandboxCreateFailedReason = "SandboxCreateFailed"
+)
+
+// probeFailures tracks the liveness probe failures seen for one pod
+type probeFailures struct {
+	// uid is the pod the failures were seen for; a recreated pod starts over
+	uid types.UID
+	// counts is the last seen count of each event, so repeated updates only add the increase
+	counts map[string]int32
+	hits   []time.Time
+}
+
+// livenessTracker counts liveness probe failures per pod within a window
+type livenessTracker struct {
+	mu   sync.Mutex
+	pods map[string]*probeFailures
+}
+
+// watchPodEvents adds an informer on the events recorded against pods in namespace
+func (c *Controller) watchPodEvents(clientset kubernetes.Interface, namespace string) {
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, c.cfg.ResyncPeriod,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("involvedObject.kind", "Pod").String()
+		}))
+	informer := factory.Core().V1().Events().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.onPodEvent,
+		UpdateFunc: func(_, newObj interface{}) { c.onPodEvent(newObj) },
+	})
+	c.auxInformers = append(c.auxInformers, informer)
+}
+
+// onPodEvent is called for every added or updated pod event
+func (c *Controller) onPodEvent(obj interface{}) {
+	ev, ok := obj.(*corev1.Event)
+	if !ok {
+		return
+	}
+	if c.cfg.LivenessEvents && ev.Reason == unhealthyEventReason && strings.HasPrefix(ev.Message, "Liveness probe failed") {
+		c.checkLivenessEvent(ev)
+	}
+	if c.cfg.SandboxEvents && ev.Reason == failedSandboxEventReason {
+		c.checkSandboxEvent(ev)
+	}
+}
+
+// checkLivenessEvent alerts once a pod's liveness probe failed often enough within the window
+func (c *Controller) checkLivenessEvent(ev *corev1.Event) {
+	podKey := ev.InvolvedObject.Namespace + "/" + ev.InvolvedObject.Name
+	// Failures are counted from the initial list on, but only alerted on once armed
+	if !c.addsArmed.Load() {
+		c.liveness.observe(podKey, ev, c.clock.Now(), c.cfg.LivenessFailureWindow)
+		return
+	}
+
+	pod, ok := c.getPod(podKey)
+	if !ok || pod.UID != ev.InvolvedObject.UID {
+		// A late event for a pod onDelete already forgot must not track it again
+		if !ok {
+			c.liveness.forget(podKey)
+		}
+		return
+	}
+	if c.liveness.observe(podKey, ev, c.clock.Now(), c.cfg.LivenessFailureWindow) < c.cfg.LivenessFailureThreshold {
+		return
+	}
+	log.Printf("TRIGGER_CHECK: Pod %s liveness probe is failing repeatedly", podKey)
+	c.checkAndTrigger(pod, badState{
+		Reason:    livenessProbeFailingReason,
+		Container: eventContainer(ev),
+		Detail:    truncate(ev.Message, badStateDetailLimit),
+	})
+}
+
+// checkSandboxEvent alerts on a pod whose sandbox could not be created. Such a pod
+// never gets container statuses, so checkPodBadState has nothing to go on.
+func (c *Controller) checkSandboxEvent(ev *corev1.Event) {
+	if !c.addsArmed.Load() {
+		return
+	}
+	podKey := ev.InvolvedObject.Namespace + "/" + ev.InvolvedObject.Name
+	pod, ok := c.getPod(podKey)
+	if !ok || pod.UID != ev.InvolvedObject.UID || pod.Status.Phase != corev1.PodPending {
+		// The kubelet retries; a pod that got past Pending has its sandbox now
+		return
+	}
+	log.Printf("TRIGGER_CHECK: Pod %s failed to create its sandbox on node %s", podKey, pod.Spec.NodeName)
+	// The message carries the CNI or runtime error, the actionable part
+	c.checkAndTrigger(pod, badState{
+		Reason: sandboxCreateFailedReason,
+		Detail: truncate(ev.Message, badStateDetailLimit),
+		Since:  ev.FirstTimestamp.Time,
+	})
+}
+
+// observe records the increase in the event's count and returns the failures seen within the window
+func (t *livenessTracker) observe(podKey string, ev *corev1.Event, now time.Time, window time.Duration) int {
+	count := ev.Count
+	if ev.Series != nil && ev.Series.Count > count {
+		count = ev.Series.Count
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pods == nil {
+		t.pods = make(map[string]*probeFailures)
+	}
+	pf, ok := t.pods[podKey]
+	if !ok || pf.uid != ev.InvolvedObject.UID {
+		pf = &probeFailures{uid: ev.InvolvedObject.UID, counts: make(map[string]int32)}
+		t.pods[podKey] = pf
+	}
+	cutoff := now.Add(-window)
+	seen, known := pf.counts[string(ev.UID)]
+	if !known && eventTime(ev).Before(cutoff) {
+		// An old event from the initial list only sets the baseline
+		seen = count
+	}
+	for i := seen; i < count; i++ {
+		pf.hits = append(pf.hits, now)
+	}
+	pf.counts[string(ev.UID)] = count
+
+	for len(pf.hits) > 0 && pf.hits[0].Before(cutoff) {
+		pf.hits = pf.hits[1:]
+	}
+	return len(pf.hits)
+}
+
+// forget drops the failures tracked for a deleted pod
+func (t *livenessTracker) forget(podKey string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.pods, podKey)
+}
+
+// prune drops the failures tracked for every pod keep rejects
+func (t *livenessTracker) prune(keep func(podKey string, uid types.UID) bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for podKey, pf := range t.pods {
+		if !keep(podKey, pf.uid) {
+			delete(t.pods, podKey)
+		}
+	}
+}
+
+// getPod looks the pod up in the informer stores
+func (c *Controller) getPod(podKey string) (*corev1.Pod, bool) {
+	for _, inf := range c.Informers {
+		obj, exists, err := inf.GetStore().GetByKey(podKey)
+		if err != nil || !exists {
+			continue
+		}
+		if pod, ok := obj.(*corev1.Pod); ok {
+			return pod, true
+		}
+	}
+	return nil, false
+}
+
+// eventContainer extracts the container name from an event's field path, e.g. "spec.containers{app}"
+func eventContainer(ev *corev1.Event) string {
+	path := ev.InvolvedObject.FieldPath
+	start := strings.Index(path, "{")
+	if start < 0 || !strings.HasSuffix(path, "}") {
+		return ""
+	}
+	return path[start+1 : len(path)-1]
+}
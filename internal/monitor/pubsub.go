@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+)
+
+// PubSubNotifier publishes alerts as JSON to a GCP Pub/Sub topic, with namespace, reason and severity attributes.
+// Batching is left to the client; pending messages are flushed on Close.
+type PubSubNotifier struct {
+	client *pubsub.Client
+	topic  *pubsub.Topic
+}
+
+// NewPubSubNotifier connects to the topic, authenticating with Application Default Credentials (Workload Identity in-cluster).
+// It fails if the topic does not exist or can't be checked.
+func NewPubSubNotifier(ctx context.Context, projectID, topicID string) (*PubSubNotifier, error) {
+	if projectID == "" || topicID == "" {
+		return nil, fmt.Errorf("PUBSUB_PROJECT and PUBSUB_TOPIC must both be set, got project %q and topic %q", projectID, topicID)
+	}
+
+	client, err := pubsub.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Pub/Sub client for project %s: %w", projectID, err)
+	}
+
+	topic := client.Topic(topicID)
+	checkCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	exists, err := topic.Exists(checkCtx)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to check Pub/Sub topic %s: %w", topic, err)
+	}
+	if !exists {
+		client.Close()
+		return nil, fmt.Errorf("pub/sub topic %s does not exist", topic)
+	}
+
+	return &PubSubNotifier{client: client, topic: topic}, nil
+}
+
+// Name implements Notifier
+func (n *PubSubNotifier) Name() string {
+	return "pubsub"
+}
+
+// Notify implements Notifier
+func (n *PubSubNotifier) Notify(ctx context.Context, alert *Alert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON for pod %s: %w", alert.PodName, err)
+	}
+
+	attrs := map[string]string{
+		"namespace": alert.Namespace,
+		"reason":    alert.Reason,
+		"severity":  string(alert.Severity),
+	}
+	for k, v := range alert.StaticLabels {
+		attrs[k] = v
+	}
+	result := n.topic.Publish(ctx, &pubsub.Message{
+		Data:       payload,
+		Attributes: attrs,
+	})
+	if _, err := result.Get(ctx); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", n.topic, err)
+	}
+	return nil
+}
+
+// Close flushes pending messages and closes the client
+func (n *PubSubNotifier) Close() error {
+	n.topic.Stop()
+	return n.client.Close()
+}
@@ -0,0 +1,172 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisAlertCache is an AlertCache shared by every replica through Redis
+type RedisAlertCache struct {
+	client *redis.Client
+	prefix string
+	clock  Clock
+}
+
+// NewRedisAlertCache connects to the Redis server at url (redis://[user:pass@]host:port/db)
+func NewRedisAlertCache(ctx context.Context, url, prefix string) (*RedisAlertCache, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis at %s: %w", opts.Addr, err)
+	}
+	return &RedisAlertCache{client: client, prefix: prefix, clock: realClock{}}, nil
+}
+
+// SetClock replaces the wall clock the cache's cooldowns are measured with
+func (r *RedisAlertCache) SetClock(clock Clock) {
+	r.clock = clock
+}
+
+// ShouldAlert implements AlertCache
+func (r *RedisAlertCache) ShouldAlert(ctx context.Context, key, reason string) (bool, AlertKind, error) {
+	last, ok, err := r.Get(ctx, key)
+	if err != nil {
+		return false, "", err
+	}
+	should, kind := decideAlert(last, ok, r.clock.Now(), reason)
+	return should, kind, nil
+}
+
+// Record implements AlertCache
+func (r *RedisAlertCache) Record(ctx context.Context, key, reason string, ttl time.Duration) error {
+	entry, retain := newCacheEntry(r.clock.Now(), reason, ttl)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.prefix+key, data, retain).Err()
+}
+
+// redisReserveAttempts bounds how often Reserve retries when another replica
+// changed the key between its read and its write
+const redisReserveAttempts = 5
+
+// Reserve implements AlertCache with an optimistic transaction on the key, so
+// replicas sharing the cache can't both win
+func (r *RedisAlertCache) Reserve(ctx context.Context, key, reason string, ttl time.Duration) (Reservation, error) {
+	var res Reservation
+	reserve := func(tx *redis.Tx) error {
+		last, ok, err := r.get(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		now := r.clock.Now()
+		entry, retain := newCacheEntry(now, reason, ttl)
+		res = Reservation{Last: last, Existed: ok, Entry: entry}
+		if res.Won, res.Kind = decideAlert(last, ok, now, reason); !res.Won {
+			return nil
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.Set(ctx, r.prefix+key, data, retain).Err()
+		})
+		return err
+	}
+	for attempt := 0; attempt < redisReserveAttempts; attempt++ {
+		err := r.client.Watch(ctx, reserve, r.prefix+key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return res, err
+		}
+	}
+	// Other replicas kept writing the key, so one of them is alerting for it
+	return Reservation{}, nil
+}
+
+// Release implements AlertCache with an optimistic transaction on the key, so a
+// reservation another replica has replaced since is left alone
+func (r *RedisAlertCache) Release(ctx context.Context, key string, res Reservation) error {
+	release := func(tx *redis.Tx) error {
+		current, ok, err := r.get(ctx, tx, key)
+		if err != nil || !ok || !sameEntry(current, res.Entry) {
+			return err
+		}
+		retain := res.Last.Until.Sub(r.clock.Now()) + alertCacheRetention
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if !res.Existed || retain <= 0 {
+				return pipe.Del(ctx, r.prefix+key).Err()
+			}
+			data, err := json.Marshal(res.Last)
+			if err != nil {
+				return err
+			}
+			return pipe.Set(ctx, r.prefix+key, data, retain).Err()
+		})
+		return err
+	}
+	err := r.client.Watch(ctx, release, r.prefix+key)
+	if errors.Is(err, redis.TxFailedErr) {
+		// The key was written while we looked, so it is no longer our reservation
+		return nil
+	}
+	return err
+}
+
+// Get implements AlertCache
+func (r *RedisAlertCache) Get(ctx context.Context, key string) (AlertCacheEntry, bool, error) {
+	return r.get(ctx, r.client, key)
+}
+
+// get reads key through cmd, which is the client or a transaction watching the key
+func (r *RedisAlertCache) get(ctx context.Context, cmd redis.Cmdable, key string) (AlertCacheEntry, bool, error) {
+	data, err := cmd.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return AlertCacheEntry{}, false, nil
+	}
+	if err != nil {
+		return AlertCacheEntry{}, false, err
+	}
+	var entry AlertCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return AlertCacheEntry{}, false, fmt.Errorf("invalid cache entry %s: %w", key, err)
+	}
+	return entry, true, nil
+}
+
+// Clear implements AlertCache
+func (r *RedisAlertCache) Clear(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
+
+// Range implements AlertCache by scanning the keys under the prefix
+func (r *RedisAlertCache) Range(ctx context.Context, fn func(key string, entry AlertCacheEntry) bool) error {
+	iter := r.client.Scan(ctx, 0, r.prefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		key := strings.TrimPrefix(iter.Val(), r.prefix)
+		entry, ok, err := r.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if ok && !fn(key, entry) {
+			return nil
+		}
+	}
+	return iter.Err()
+}
+
+// Close closes the connection to Redis
+func (r *RedisAlertCache) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,39 @@
+package monitor
+
+import (
+	"context"
+	"time"
+)
+
+// withRetryDeadline bounds every attempt and backoff for one alert by
+// AGENT_RETRY_DEADLINE, so a failing agent can't hold an alert indefinitely
+func withRetryDeadline(ctx context.Context, deadline time.Duration) (context.Context, context.CancelFunc) {
+	if deadline <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, deadline)
+}
+
+// capBackoff limits a retry backoff to AGENT_RETRY_MAX_BACKOFF, when set
+func capBackoff(backoff, max time.Duration) time.Duration {
+	if max > 0 && backoff > max {
+		return max
+	}
+	return backoff
+}
+
+// waitRetry waits out the backoff before the next attempt. It gives up at
+// once when the context would expire first, instead of sleeping until then.
+func waitRetry(ctx context.Context, backoff time.Duration) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+		return context.DeadlineExceeded
+	}
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
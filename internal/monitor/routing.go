@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// NotifierRoute sends the alerts of pods matching a label selector to a subset of the notifiers
+type NotifierRoute struct {
+	Selector  labels.Selector
+	Notifiers []string
+
+	spec string
+}
+
+// String describes the route for logs
+func (r NotifierRoute) String() string {
+	return r.spec
+}
+
+// ParseNotifierRoutes parses "selector:notifier|notifier;selector:notifier",
+// e.g. "oncall-team=storage:agent|sns;tier in (batch):nats"
+func ParseNotifierRoutes(spec string) ([]NotifierRoute, error) {
+	var routes []NotifierRoute
+	for _, rule := range strings.Split(spec, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		i := strings.LastIndex(rule, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid route %q: expected selector:notifier|notifier", rule)
+		}
+		selector, err := labels.Parse(strings.TrimSpace(rule[:i]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector in route %q: %w", rule, err)
+		}
+		if selector.Empty() {
+			return nil, fmt.Errorf("invalid route %q: the selector must not be empty", rule)
+		}
+		route := NotifierRoute{Selector: selector, spec: rule}
+		for _, name := range strings.Split(rule[i+1:], "|") {
+			if name = strings.TrimSpace(name); name != "" {
+				route.Notifiers = append(route.Notifiers, name)
+			}
+		}
+		if len(route.Notifiers) == 0 {
+			return nil, fmt.Errorf("invalid route %q: no notifiers", rule)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// routeAlert restricts the alert to the notifiers of the first NOTIFIER_ROUTES
+// route matching the pod's labels; unmatched alerts go to every notifier
+func (c *Controller) routeAlert(pod *corev1.Pod, alert *Alert) {
+	set := labels.Set(pod.Labels)
+	for _, route := range c.cfg.NotifierRoutes {
+		if !route.Selector.Matches(set) {
+			continue
+		}
+		alert.routes = make(map[string]bool, len(route.Notifiers))
+		for _, name := range route.Notifiers {
+			alert.routes[name] = true
+		}
+		return
+	}
+}
@@ -0,0 +1,96 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+)
+
+// responseSample is one sampled agent exchange, written as a line of JSON
+type responseSample struct {
+	Pod       string          `json:"pod"`
+	SampledAt time.Time       `json:"sampled_at"`
+	Request   json.RawMessage `json:"request"`
+	Response  json.RawMessage `json:"response"`
+}
+
+// ResponseSampler writes a random fraction of agent request/response pairs to a sink,
+// building a dataset for reviewing the agent's prompts without storing every alert
+type ResponseSampler struct {
+	rate     float64
+	fieldMap map[string]string
+	clock    Clock
+
+	mu  sync.Mutex
+	out io.Writer
+	// file is set when the sink is a file we opened
+	file *os.File
+}
+
+// NewResponseSampler opens the AGENT_SAMPLE_SINK configured in cfg
+func NewResponseSampler(cfg *Config) (*ResponseSampler, error) {
+	s := &ResponseSampler{
+		rate:     cfg.AgentSampleRate,
+		fieldMap: cfg.AgentFieldMap,
+		clock:    realClock{},
+	}
+	if cfg.AgentSampleSink == "stdout" {
+		s.out = os.Stdout
+		return s, nil
+	}
+	f, err := os.OpenFile(cfg.AgentSampleSink, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open agent sample sink %s: %w", cfg.AgentSampleSink, err)
+	}
+	s.out = f
+	s.file = f
+	return s, nil
+}
+
+// maybeRecord writes the exchange to the sink for a random AGENT_SAMPLE_RATE fraction of calls
+func (s *ResponseSampler) maybeRecord(alert *Alert, body []byte) {
+	if rand.Float64() >= s.rate {
+		return
+	}
+	podKey := alert.Namespace + "/" + alert.PodName
+
+	request, err := marshalAlert(alert, s.fieldMap)
+	if err != nil {
+		log.Printf("WARNING: Failed to marshal sampled request for %s: %v", podKey, err)
+		return
+	}
+	response := json.RawMessage(body)
+	if !json.Valid(body) {
+		// Keep non-JSON responses as a string so every line stays valid JSON
+		response, _ = json.Marshal(string(body))
+	}
+	line, err := json.Marshal(responseSample{
+		Pod:       podKey,
+		SampledAt: s.clock.Now().UTC(),
+		Request:   request,
+		Response:  response,
+	})
+	if err != nil {
+		log.Printf("WARNING: Failed to marshal agent sample for %s: %v", podKey, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.out.Write(append(line, '\n')); err != nil {
+		log.Printf("WARNING: Failed to write agent sample for %s: %v", podKey, err)
+	}
+}
+
+// Close closes the sink if it is a file
+func (s *ResponseSampler) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// selfTestImage can never be pulled, so the self-test pod goes into ImagePullBackOff
+const selfTestImage = "watch-my-pod.invalid/selftest:does-not-exist"
+
+// selfTestResult is what the self-test notifiers saw for the self-test pod
+type selfTestResult struct {
+	mu        sync.Mutex
+	reason    string
+	errs      map[string]error
+	delivered []string
+	done      chan struct{}
+	closed    bool
+}
+
+// record stores one notifier's outcome; the first successful delivery ends the wait
+func (r *selfTestResult) record(name, reason string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reason = reason
+	if err != nil {
+		r.errs[name] = err
+		return
+	}
+	r.delivered = append(r.delivered, name)
+	if !r.closed {
+		r.closed = true
+		close(r.done)
+	}
+}
+
+// selfTestNotifier passes alerts for the self-test pod on to a configured
+// notifier, marked as test alerts, and records whether they were delivered.
+// Alerts for other pods are dropped, so a self-test never pages for them.
+type selfTestNotifier struct {
+	Notifier
+	podName string
+	result  *selfTestResult
+}
+
+// Notify implements Notifier
+func (n *selfTestNotifier) Notify(ctx context.Context, alert *Alert) error {
+	if alert.PodName != n.podName {
+		return nil
+	}
+	test := *alert
+	test.Test = true
+	err := n.Notifier.Notify(ctx, &test)
+	n.result.record(n.Name(), alert.Reason, err)
+	return err
+}
+
+// SelfTest checks the monitor's wiring end to end: it creates a pod with an
+// image that cannot be pulled in SELFTEST_NAMESPACE, waits for the controller
+// to detect it and at least one notifier to deliver the alert, then deletes the
+// pod. Each step is written to w; it returns false if any failed.
+func SelfTest(ctx context.Context, clientset kubernetes.Interface, cfg *Config, notifiers []Notifier, w io.Writer) bool {
+	pods := clientset.CoreV1().Pods(cfg.SelfTestNamespace)
+	pod, err := pods.Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "watch-my-pod-selftest-",
+			Labels:       map[string]string{"app.kubernetes.io/name": "watch-my-pod-selftest"},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			TerminationGracePeriodSeconds: new(int64),
+			Containers: []corev1.Container{{
+				Name:  "selftest",
+				Image: selfTestImage,
+			}},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		fmt.Fprintf(w, "FAIL create pod in namespace %s: %v\n", cfg.SelfTestNamespace, err)
+		return false
+	}
+	fmt.Fprintf(w, "OK   create pod %s/%s\n", pod.Namespace, pod.Name)
+	defer func() {
+		// The caller's context may be done by now, but the pod must not be left behind
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := pods.Delete(cleanupCtx, pod.Name, metav1.DeleteOptions{}); err != nil {
+			fmt.Fprintf(w, "FAIL delete pod %s/%s: %v\n", pod.Namespace, pod.Name, err)
+			return
+		}
+		fmt.Fprintf(w, "OK   delete pod %s/%s\n", pod.Namespace, pod.Name)
+	}()
+
+	result := &selfTestResult{errs: make(map[string]error), done: make(chan struct{})}
+	wrapped := make([]Notifier, len(notifiers))
+	for i, n := range notifiers {
+		wrapped[i] = &selfTestNotifier{Notifier: n, podName: pod.Name, result: result}
+	}
+
+	// Watch only the self-test namespace, and alert on new pods at once.
+	// Filters, rules and routing are off so the test pod's alert reaches every notifier.
+	testCfg := *cfg
+	testCfg.WatchNamespaces = []string{cfg.SelfTestNamespace}
+	testCfg.StartupGrace = 0
+	testCfg.HeartbeatInterval = 0
+	testCfg.InventoryFile = ""
+	testCfg.MinPodAge = 0
+	testCfg.ReasonDebounce = nil
+	testCfg.SuppressionConfigMap = ""
+	testCfg.WatchAlertRules = false
+	testCfg.MaintenanceWindows = nil
+	testCfg.NotifierRoutes = nil
+	testCfg.NotifierMinSeverities = nil
+	testCfg.CorrelationLabel = ""
+	testCfg.NodeCorrelation = false
+	controller := NewController(clientset, &testCfg, wrapped)
+
+	stopCh := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		controller.Run(stopCh)
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(cfg.SelfTestTimeout)
+	defer timer.Stop()
+	select {
+	case <-result.done:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	close(stopCh)
+	<-stopped
+
+	result.mu.Lock()
+	defer result.mu.Unlock()
+	if result.reason == "" {
+		fmt.Fprintf(w, "FAIL detect pod %s/%s: no alert within %v\n", pod.Namespace, pod.Name, cfg.SelfTestTimeout)
+		return false
+	}
+	fmt.Fprintf(w, "OK   detect pod %s/%s: %s\n", pod.Namespace, pod.Name, result.reason)
+	for name, err := range result.errs {
+		fmt.Fprintf(w, "FAIL notify %s: %v\n", name, err)
+	}
+	for _, name := range result.delivered {
+		fmt.Fprintf(w, "OK   notify %s\n", name)
+	}
+	if len(result.delivered) == 0 {
+		fmt.Fprintf(w, "FAIL notify: no notifier delivered the alert within %v\n", cfg.SelfTestTimeout)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// severityAnnotation lets a team set the severity of their pod's alerts
+const severityAnnotation = "watch-my-pod/severity"
+
+// Severity is how urgent an alert is
+type Severity string
+
+// Known severities, from least to most urgent
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// severityRank orders the known severities
+var severityRank = map[Severity]int{
+	SeverityInfo:     0,
+	SeverityWarning:  1,
+	SeverityCritical: 2,
+}
+
+// defaultReasonSeverities is the built-in reason to severity map, overridable with SEVERITY_MAP
+var defaultReasonSeverities = map[string]Severity{
+	"CrashLoopBackOff":     SeverityCritical,
+	crashLoopWarningReason: SeverityInfo,
+}
+
+// ParseSeverity validates a severity name
+func ParseSeverity(s string) (Severity, error) {
+	sev := Severity(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := severityRank[sev]; !ok {
+		return "", fmt.Errorf("unknown severity %q: expected info, warning or critical", s)
+	}
+	return sev, nil
+}
+
+// AtLeast reports whether s is as urgent as other
+func (s Severity) AtLeast(other Severity) bool {
+	return severityRank[s] >= severityRank[other]
+}
+
+// severityFor computes the severity of an alert for the given reason in the given namespace
+func (c *Controller) severityFor(namespace, reason string) Severity {
+	sev, ok := c.cfg.ReasonSeverities[reason]
+	if !ok {
+		sev = c.cfg.DefaultSeverity
+	}
+
+	return c.criticalFloor(namespace, sev)
+}
+
+// criticalFloor raises sev to CRITICAL_NAMESPACE_SEVERITY in a critical namespace.
+// Failures in critical namespaces are never below the floor, whatever the reason.
+func (c *Controller) criticalFloor(namespace string, sev Severity) Severity {
+	if matchesAny(c.cfg.CriticalNamespaces, namespace) && !sev.AtLeast(c.cfg.CriticalNamespaceSeverity) {
+		return c.cfg.CriticalNamespaceSeverity
+	}
+	return sev
+}
+
+// podSeverity computes the severity of an alert for the pod. A valid
+// watch-my-pod/severity annotation wins over everything else.
+func (c *Controller) podSeverity(pod *corev1.Pod, reason string) Severity {
+	if sev, ok := annotatedSeverity(pod); ok {
+		return sev
+	}
+	return c.severityFor(pod.Namespace, reason)
+}
+
+// ruleSeverity is podSeverity with the severity of the PodAlertRule matching the pod,
+// if any. The rule wins over SEVERITY_MAP but not over the critical namespace floor.
+func (c *Controller) ruleSeverity(pod *corev1.Pod, reason string, rule *alertRule) Severity {
+	if _, annotated := annotatedSeverity(pod); rule != nil && rule.severity != "" && !annotated {
+		return c.criticalFloor(pod.Namespace, rule.severity)
+	}
+	return c.podSeverity(pod, reason)
+}
+
+// annotatedSeverity returns the pod's watch-my-pod/severity annotation, if it is valid
+func annotatedSeverity(pod *corev1.Pod) (Severity, bool) {
+	v, ok := pod.Annotations[severityAnnotation]
+	if !ok {
+		return "", false
+	}
+	sev, err := ParseSeverity(v)
+	if err != nil {
+		log.Printf("WARNING: Ignoring invalid %s annotation %q on pod %s/%s: %v", severityAnnotation, v, pod.Namespace, pod.Name, err)
+		return "", false
+	}
+	return sev, true
+}
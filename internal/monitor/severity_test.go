@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRuleSeverity(t *testing.T) {
+	c := &Controller{cfg: &Config{
+		DefaultSeverity:           SeverityWarning,
+		CriticalNamespaces:        []string{"payments"},
+		CriticalNamespaceSeverity: SeverityCritical,
+	}}
+	info := &alertRule{severity: SeverityInfo}
+
+	tests := []struct {
+		name       string
+		namespace  string
+		annotation string
+		rule       *alertRule
+		want       Severity
+	}{
+		{name: "default", namespace: "shop", want: SeverityWarning},
+		{name: "rule", namespace: "shop", rule: info, want: SeverityInfo},
+		{name: "critical namespace", namespace: "payments", want: SeverityCritical},
+		{name: "rule can't lower a critical namespace", namespace: "payments", rule: info, want: SeverityCritical},
+		{name: "annotation wins over rule", namespace: "shop", annotation: "critical", rule: info, want: SeverityCritical},
+		{name: "invalid annotation keeps rule", namespace: "shop", annotation: "loud", rule: info, want: SeverityInfo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: tt.namespace, Name: "web"}}
+			if tt.annotation != "" {
+				pod.Annotations = map[string]string{severityAnnotation: tt.annotation}
+			}
+			if got := c.ruleSeverity(pod, "OOMKilled", tt.rule); got != tt.want {
+				t.Errorf("ruleSeverity() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
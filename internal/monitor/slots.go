@@ -0,0 +1,61 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// errQueueAgeExceeded is returned for alerts that waited too long for an agent slot
+var errQueueAgeExceeded = errors.New("waited longer than AGENT_MAX_QUEUE_AGE for an agent slot")
+
+// agentSlots bounds the number of concurrent agent calls. When the agent is
+// slow, waiting alerts are dropped once they are older than maxAge, so the
+// slots go to fresh alerts instead of ones nobody needs anymore.
+type agentSlots struct {
+	sem    chan struct{}
+	maxAge time.Duration
+	clock  Clock
+}
+
+// newAgentSlots creates the slots for one agent
+func newAgentSlots(cfg *Config) *agentSlots {
+	return &agentSlots{
+		sem:    make(chan struct{}, cfg.MaxConcurrentAgentCalls),
+		maxAge: cfg.AgentMaxQueueAge,
+		clock:  realClock{},
+	}
+}
+
+// acquire waits for a free slot, giving up on shutdown or once the alert has
+// been queued for longer than maxAge. queued is when the alert was handed to
+// the notifier, so the age spans every retry of the same alert.
+func (s *agentSlots) acquire(ctx context.Context, queued time.Time) error {
+	var expired <-chan time.Time
+	if s.maxAge > 0 {
+		left := s.maxAge - s.clock.Since(queued)
+		if left <= 0 {
+			agentQueueDropped.Inc()
+			return errQueueAgeExceeded
+		}
+		timer := time.NewTimer(left)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case s.sem <- struct{}{}:
+		agentCallsInFlight.Inc()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-expired:
+		agentQueueDropped.Inc()
+		return errQueueAgeExceeded
+	}
+}
+
+// release frees a slot taken by acquire
+func (s *agentSlots) release() {
+	agentCallsInFlight.Dec()
+	<-s.sem
+}
@@ -0,0 +1,30 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAgentSlotsMaxQueueAge(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	slots := newAgentSlots(&Config{MaxConcurrentAgentCalls: 1, AgentMaxQueueAge: time.Minute})
+	slots.clock = clock
+	ctx := context.Background()
+
+	queued := clock.Now()
+	if err := slots.acquire(ctx, queued); err != nil {
+		t.Fatalf("acquire() of a fresh alert error = %v", err)
+	}
+	slots.release()
+
+	// The age spans every retry, so a free slot doesn't help an alert queued too long ago
+	clock.Advance(time.Minute)
+	if err := slots.acquire(ctx, queued); !errors.Is(err, errQueueAgeExceeded) {
+		t.Errorf("acquire() after AGENT_MAX_QUEUE_AGE = %v, want errQueueAgeExceeded", err)
+	}
+	if err := slots.acquire(ctx, clock.Now()); err != nil {
+		t.Errorf("acquire() of a newer alert error = %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+// snsSubjectLimit is the longest subject SNS accepts
+const snsSubjectLimit = 100
+
+// SNSNotifier publishes alerts as JSON to an AWS SNS topic.
+// Namespace, reason and severity are set as message attributes so subscription filter policies can route on them.
+type SNSNotifier struct {
+	client   *sns.Client
+	topicARN string
+	format   MessageFormat
+}
+
+// NewSNSNotifier creates a notifier for the topic, authenticating with the standard AWS credential chain (IRSA in-cluster)
+func NewSNSNotifier(ctx context.Context, topicARN string, format MessageFormat) (*SNSNotifier, error) {
+	parsed, err := arn.Parse(topicARN)
+	if err != nil || parsed.Service != "sns" {
+		return nil, fmt.Errorf("invalid SNS_TOPIC_ARN %q: expected arn:aws:sns:<region>:<account>:<topic>", topicARN)
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(parsed.Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	return &SNSNotifier{client: sns.NewFromConfig(awsCfg), topicARN: topicARN, format: format}, nil
+}
+
+// Name implements Notifier
+func (n *SNSNotifier) Name() string {
+	return "sns"
+}
+
+// Notify implements Notifier
+func (n *SNSNotifier) Notify(ctx context.Context, alert *Alert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON for pod %s: %w", alert.PodName, err)
+	}
+
+	_, err = n.client.Publish(ctx, &sns.PublishInput{
+		TopicArn:          aws.String(n.topicARN),
+		Subject:           aws.String(n.format.Apply(fmt.Sprintf("Pod %s/%s: %s", alert.Namespace, alert.PodName, alert.Reason), snsSubjectLimit)),
+		Message:           aws.String(string(payload)),
+		MessageAttributes: snsAttributes(alert),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", n.topicARN, err)
+	}
+	return nil
+}
+
+// snsAttributes are the message attributes subscription filters can match on
+func snsAttributes(alert *Alert) map[string]snstypes.MessageAttributeValue {
+	attrs := map[string]snstypes.MessageAttributeValue{
+		"namespace": snsString(alert.Namespace),
+		"reason":    snsString(alert.Reason),
+		"severity":  snsString(string(alert.Severity)),
+	}
+	for k, v := range alert.StaticLabels {
+		attrs[k] = snsString(v)
+	}
+	return attrs
+}
+
+// snsString builds a string message attribute
+func snsString(v string) snstypes.MessageAttributeValue {
+	return snstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(v)}
+}
@@ -0,0 +1,32 @@
+package monitor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// staticLabelName is the Prometheus label name syntax, which static labels
+// must follow since they are added to every metric
+var staticLabelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// metricLabelNames are the labels the monitor's own metrics use
+var metricLabelNames = []string{"environment", "field", "reason", "event", "state", "set", "kind", "decision", "notifier", "result", "le"}
+
+// validateStaticLabels checks that STATIC_LABELS keys are valid label names and
+// don't collide with an alert field, a message attribute or a metric label
+func validateStaticLabels(labels map[string]string) error {
+	reserved := alertFieldNames()
+	for _, name := range metricLabelNames {
+		reserved[name] = true
+	}
+	for k := range labels {
+		if !staticLabelName.MatchString(k) || strings.HasPrefix(k, "__") {
+			return fmt.Errorf("label %q is not a valid label name", k)
+		}
+		if reserved[k] {
+			return fmt.Errorf("label %q collides with a field the monitor sets itself", k)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// AgentAction is what the monitor does with an agent response status
+type AgentAction string
+
+const (
+	// AgentActionSuccess counts the alert as delivered
+	AgentActionSuccess AgentAction = "success"
+	// AgentActionRetry retries the request with backoff
+	AgentActionRetry AgentAction = "retry"
+	// AgentActionDrop fails the alert without retrying, logging the agent's answer as an error
+	AgentActionDrop AgentAction = "drop"
+)
+
+// parseAgentStatusActions parses AGENT_STATUS_ACTIONS, e.g. "409=success,422=drop"
+func parseAgentStatusActions(key string) (map[int]AgentAction, error) {
+	pairs, err := envStringMap(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int]AgentAction, len(pairs))
+	for rawCode, rawAction := range pairs {
+		code, err := strconv.Atoi(rawCode)
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid %s entry %q: expected an HTTP status code", key, rawCode)
+		}
+		action := AgentAction(strings.ToLower(rawAction))
+		switch action {
+		case AgentActionSuccess, AgentActionRetry, AgentActionDrop:
+		default:
+			return nil, fmt.Errorf("invalid %s action %q for %d: expected success, retry or drop", key, rawAction, code)
+		}
+		out[code] = action
+	}
+	return out, nil
+}
+
+// statusAction returns the configured action for an agent response status, falling
+// back to the default contract: 2xx succeeds, 5xx and 429 are retried, the rest dropped
+func (n *AgentNotifier) statusAction(code int) AgentAction {
+	if action, ok := n.statusActions[code]; ok {
+		return action
+	}
+	switch {
+	case code >= 200 && code <= 299:
+		return AgentActionSuccess
+	case code >= 500 || code == http.StatusTooManyRequests:
+		return AgentActionRetry
+	}
+	return AgentActionDrop
+}
@@ -0,0 +1,38 @@
+package monitor
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// runStats are totals since startup, logged as a summary on shutdown
+type runStats struct {
+	started time.Time
+
+	sent       atomic.Int64
+	failed     atomic.Int64
+	resolved   atomic.Int64
+	suppressed atomic.Int64
+
+	// peakFailing is the most pods seen failing at once
+	peakFailing atomic.Int64
+}
+
+// observeFailing raises the peak failing-pod count if n exceeds it
+func (s *runStats) observeFailing(n int) {
+	for {
+		peak := s.peakFailing.Load()
+		if int64(n) <= peak || s.peakFailing.CompareAndSwap(peak, int64(n)) {
+			return
+		}
+	}
+}
+
+// logSummary logs what the monitor did since it started
+func (c *Controller) logSummary() {
+	log.Printf("SUMMARY: ran for %v, %d alerts sent (%d resolved), %d failed to deliver, %d suppressed, peak of %d failing pods",
+		c.clock.Since(c.stats.started).Round(time.Second),
+		c.stats.sent.Load(), c.stats.resolved.Load(), c.stats.failed.Load(),
+		c.stats.suppressed.Load(), c.stats.peakFailing.Load())
+}
@@ -0,0 +1,129 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/yaml"
+)
+
+// SuppressionRule silences alerts for known-broken pods.
+// Empty fields match anything; Namespace, Name and Owner accept globs.
+type SuppressionRule struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Owner     string `json:"owner,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// String describes the rule for logs
+func (r SuppressionRule) String() string {
+	return fmt.Sprintf("{namespace=%q name=%q owner=%q reason=%q}", r.Namespace, r.Name, r.Owner, r.Reason)
+}
+
+// matches reports whether the rule applies to the pod failing with reason
+func (r SuppressionRule) matches(pod *corev1.Pod, reason string) bool {
+	if !globMatch(r.Namespace, pod.Namespace) || !globMatch(r.Name, pod.Name) {
+		return false
+	}
+	if r.Owner != "" {
+		_, owner := podOwner(pod)
+		if owner == "" || !globMatch(r.Owner, owner) {
+			return false
+		}
+	}
+	return r.Reason == "" || r.Reason == reason
+}
+
+// globMatch matches value against pattern, treating an empty pattern as a wildcard
+func globMatch(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
+
+// parseSuppressionRules reads the rules from every data key of the ConfigMap.
+// Each key holds a YAML or JSON list of rules.
+func parseSuppressionRules(cm *corev1.ConfigMap) ([]SuppressionRule, error) {
+	keys := make([]string, 0, len(cm.Data))
+	for k := range cm.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var rules []SuppressionRule
+	for _, k := range keys {
+		var parsed []SuppressionRule
+		if err := yaml.Unmarshal([]byte(cm.Data[k]), &parsed); err != nil {
+			return nil, fmt.Errorf("key %q: %w", k, err)
+		}
+		for _, r := range parsed {
+			for _, p := range []string{r.Namespace, r.Name, r.Owner} {
+				if _, err := path.Match(p, ""); err != nil {
+					return nil, fmt.Errorf("key %q: invalid pattern %q: %w", k, p, err)
+				}
+			}
+		}
+		rules = append(rules, parsed...)
+	}
+	return rules, nil
+}
+
+// watchSuppressions watches the "namespace/name" ConfigMap and reloads the suppression rules whenever it changes
+func (c *Controller) watchSuppressions(clientset kubernetes.Interface, ref string) {
+	namespace, name, _ := strings.Cut(ref, "/")
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, c.cfg.ResyncPeriod,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = "metadata.name=" + name
+		}),
+	)
+	informer := factory.Core().V1().ConfigMaps().Informer()
+
+	reload := func(obj interface{}) {
+		cm, ok := obj.(*corev1.ConfigMap)
+		if !ok {
+			return
+		}
+		rules, err := parseSuppressionRules(cm)
+		if err != nil {
+			log.Printf("ERROR: Ignoring invalid suppression ConfigMap %s, keeping the previous rules: %v", ref, err)
+			return
+		}
+		c.suppressions.Store(&rules)
+		log.Printf("Loaded %d suppression rules from ConfigMap %s", len(rules), ref)
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    reload,
+		UpdateFunc: func(_, newObj interface{}) { reload(newObj) },
+		DeleteFunc: func(interface{}) {
+			c.suppressions.Store(nil)
+			log.Printf("Suppression ConfigMap %s was deleted, clearing suppression rules", ref)
+		},
+	})
+	c.auxInformers = append(c.auxInformers, informer)
+}
+
+// matchSuppression returns the first suppression rule matching the pod, or nil
+func (c *Controller) matchSuppression(pod *corev1.Pod, reason string) *SuppressionRule {
+	rules := c.suppressions.Load()
+	if rules == nil {
+		return nil
+	}
+	for i := range *rules {
+		if (*rules)[i].matches(pod, reason) {
+			return &(*rules)[i]
+		}
+	}
+	return nil
+}
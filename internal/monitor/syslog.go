@@ -0,0 +1,218 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// syslogFacility is local0, the usual facility for site-specific applications
+	syslogFacility = 16
+
+	// syslogSDID identifies the structured data element. 32473 is the private
+	// enterprise number RFC 5612 reserves for documentation and examples.
+	syslogSDID = "alert@32473"
+
+	// syslogRetryBackoff is the wait before the first retry; it doubles after each
+	syslogRetryBackoff = time.Second
+)
+
+// syslogSeverities maps alert severities onto syslog severities
+var syslogSeverities = map[Severity]int{
+	SeverityCritical: 2, // crit
+	SeverityWarning:  4, // warning
+	SeverityInfo:     6, // informational
+}
+
+// SyslogNotifier sends each alert as an RFC 5424 message to a syslog server over
+// TCP or TLS, with octet-counting framing (RFC 6587, RFC 5425). The connection
+// is made lazily and re-established after a failed write.
+type SyslogNotifier struct {
+	addr       string
+	tlsConfig  *tls.Config
+	hostname   string
+	maxRetries int
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogNotifier creates a notifier for the syslog server at SYSLOG_ADDR
+func NewSyslogNotifier(cfg *Config) (*SyslogNotifier, error) {
+	n := &SyslogNotifier{addr: cfg.SyslogAddr, hostname: "-", maxRetries: cfg.SyslogMaxRetries}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		n.hostname = syslogHeaderField(host, 255)
+	}
+	if cfg.SyslogTLS {
+		n.tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if cfg.SyslogCAFile != "" {
+			pem, err := os.ReadFile(cfg.SyslogCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read SYSLOG_CA_FILE: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in SYSLOG_CA_FILE %s", cfg.SyslogCAFile)
+			}
+			n.tlsConfig.RootCAs = pool
+		}
+	}
+	return n, nil
+}
+
+// Name implements Notifier
+func (n *SyslogNotifier) Name() string {
+	return "syslog"
+}
+
+// Notify implements Notifier, reconnecting and retrying up to SYSLOG_MAX_RETRIES times
+func (n *SyslogNotifier) Notify(ctx context.Context, alert *Alert) error {
+	msg, err := n.format(alert, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON for pod %s: %w", alert.PodName, err)
+	}
+
+	backoff := syslogRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err = n.send(ctx, msg)
+		if err == nil {
+			return nil
+		}
+		if attempt >= n.maxRetries {
+			return fmt.Errorf("failed to send to syslog server %s: %w", n.addr, err)
+		}
+		if waitRetry(ctx, backoff) != nil {
+			return fmt.Errorf("failed to send to syslog server %s: %w", n.addr, err)
+		}
+		backoff *= 2
+	}
+}
+
+// send writes one framed message, dropping the connection if the write fails
+func (n *SyslogNotifier) send(ctx context.Context, msg []byte) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn != nil && !connAlive(n.conn) {
+		n.conn.Close()
+		n.conn = nil
+	}
+	if n.conn == nil {
+		conn, err := n.dial(ctx)
+		if err != nil {
+			return err
+		}
+		n.conn = conn
+	}
+
+	// Without a context deadline this is the zero time, which clears the write deadline
+	deadline, _ := ctx.Deadline()
+	n.conn.SetWriteDeadline(deadline)
+	if _, err := fmt.Fprintf(n.conn, "%d %s", len(msg), msg); err != nil {
+		n.conn.Close()
+		n.conn = nil
+		return err
+	}
+	return nil
+}
+
+// connAlive reports whether the server still has the connection open. A write
+// to a connection the server already closed succeeds locally and is lost, so
+// this is checked before each write. Syslog servers never send anything; a
+// read that times out means the connection is still up.
+func connAlive(conn net.Conn) bool {
+	conn.SetReadDeadline(time.Now().Add(time.Millisecond))
+	defer conn.SetReadDeadline(time.Time{})
+	var buf [1]byte
+	_, err := conn.Read(buf[:])
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// dial connects to the server, over TLS if SYSLOG_TLS is set
+func (n *SyslogNotifier) dial(ctx context.Context) (net.Conn, error) {
+	if n.tlsConfig != nil {
+		d := &tls.Dialer{Config: n.tlsConfig}
+		return d.DialContext(ctx, "tcp", n.addr)
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", n.addr)
+}
+
+// format renders the alert as an RFC 5424 message: the key fields as
+// structured data, for SIEM rules, and the full alert as JSON in the body
+func (n *SyslogNotifier) format(alert *Alert, now time.Time) ([]byte, error) {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return nil, err
+	}
+	severity, ok := syslogSeverities[alert.Severity]
+	if !ok {
+		severity = syslogSeverities[SeverityWarning]
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s watch-my-pod - %s [%s",
+		syslogFacility*8+severity,
+		now.UTC().Format(time.RFC3339Nano),
+		n.hostname,
+		syslogHeaderField(alert.Reason, 32),
+		syslogSDID,
+	)
+	for _, p := range [][2]string{
+		{"namespace", alert.Namespace},
+		{"pod", alert.PodName},
+		{"reason", alert.Reason},
+		{"severity", string(alert.Severity)},
+		{"kind", string(alert.Kind)},
+		{"environment", alert.Environment},
+	} {
+		if p[1] != "" {
+			fmt.Fprintf(&b, ` %s="%s"`, p[0], syslogParamEscaper.Replace(p[1]))
+		}
+	}
+	b.WriteString("] ")
+	b.Write(payload)
+	return []byte(b.String()), nil
+}
+
+// syslogParamEscaper escapes the characters RFC 5424 reserves in SD-PARAM values
+var syslogParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// syslogHeaderField makes s a valid header field: printable ASCII without
+// spaces, at most max characters, or "-" for the nil value
+func syslogHeaderField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < '!' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// Close closes the connection to the syslog server
+func (n *SyslogNotifier) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn == nil {
+		return nil
+	}
+	err := n.conn.Close()
+	n.conn = nil
+	return err
+}
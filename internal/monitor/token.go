@@ -0,0 +1,50 @@
+package monitor
+
+import (
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// tokenSource provides the bearer token sent to the agent.
+// A token file is re-read on every call so a rotated Secret is picked up
+// without a restart; if the read fails the last good token is used.
+type tokenSource struct {
+	path string
+
+	mu   sync.Mutex
+	last string
+}
+
+// newTokenSource returns a source for the configured token, or nil if no token is configured
+func newTokenSource(cfg *Config) *tokenSource {
+	if cfg.AgentTokenFile != "" {
+		return &tokenSource{path: cfg.AgentTokenFile}
+	}
+	if cfg.AgentToken != "" {
+		return &tokenSource{last: cfg.AgentToken}
+	}
+	return nil
+}
+
+// Token returns the current token
+func (t *tokenSource) Token() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.path == "" {
+		return t.last
+	}
+
+	data, err := os.ReadFile(t.path)
+	token := strings.TrimSpace(string(data))
+	switch {
+	case err != nil:
+		log.Printf("WARNING: Failed to read agent token file %s, using the last good token: %v", t.path, err)
+	case token == "":
+		log.Printf("WARNING: Agent token file %s is empty, using the last good token", t.path)
+	default:
+		t.last = token
+	}
+	return t.last
+}
@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// tracerName names the monitor's spans
+const tracerName = "github.com/adityapore231/Watch-my-pod/internal/monitor"
+
+// startAlertSpan starts the span covering one pod alert, from deduplication to delivery
+func (c *Controller) startAlertSpan(pod *corev1.Pod, reason string, kind AlertKind) (context.Context, trace.Span) {
+	return c.tracer.Start(c.ctx, "alert",
+		trace.WithAttributes(
+			attribute.String("k8s.namespace.name", pod.Namespace),
+			attribute.String("k8s.pod.name", pod.Name),
+			attribute.String("watchmypod.reason", reason),
+			attribute.String("watchmypod.kind", string(kind)),
+		))
+}
+
+// countTriggered counts a triggered alert. When the alert's span is sampled its
+// trace ID is attached as an exemplar, linking a spike in the metric to a trace.
+func countTriggered(ctx context.Context, kind AlertKind) {
+	counter := alertsTriggered.WithLabelValues(string(kind))
+	sc := trace.SpanContextFromContext(ctx)
+	if adder, ok := counter.(prometheus.ExemplarAdder); ok && sc.IsSampled() {
+		adder.AddWithExemplar(1, prometheus.Labels{"trace_id": sc.TraceID().String()})
+		return
+	}
+	counter.Inc()
+}
+
+// defaultTracer uses the global tracer provider, a no-op unless one is installed
+func defaultTracer() trace.Tracer {
+	return otel.GetTracerProvider().Tracer(tracerName)
+}
+
+// NewTracerProvider builds an SDK tracer provider exporting over OTLP/gRPC, configured
+// by the standard OTEL_* variables. It returns nil, and tracing stays a no-op, unless
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set. The
+// exporter reads its endpoint, headers and TLS settings from the environment, and the
+// sampler comes from OTEL_TRACES_SAMPLER.
+func NewTracerProvider(ctx context.Context) (*sdktrace.TracerProvider, error) {
+	if os.Getenv("OTEL_SDK_DISABLED") == "true" || os.Getenv("OTEL_TRACES_EXPORTER") == "none" {
+		return nil, nil
+	}
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return nil, nil
+	}
+	exporter, err := otlptracegrpc.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the default service name
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attribute.String("service.name", "watch-my-pod")),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTEL_RESOURCE_ATTRIBUTES: %w", err)
+	}
+	return sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res)), nil
+}
+
+// InstallTracerProvider makes tp the global tracer provider and propagates
+// trace context to the agent in W3C traceparent headers
+func InstallTracerProvider(tp trace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// Validate checks the resolved configuration as a whole, after every variable
+// has been parsed: the remaining range checks, and settings that only make
+// sense together. It returns the first invalid setting and logs a warning for
+// values that are valid but usually a mistake. LoadConfig calls it; call it
+// again after changing a Config by hand.
+func (cfg *Config) Validate() error {
+	nonNegative := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"CONTAINER_CREATING_TIMEOUT", cfg.ContainerCreatingTimeout},
+		{"WAITING_TIMEOUT", cfg.WaitingTimeout},
+		{"AGENT_RETRY_MAX_BACKOFF", cfg.AgentRetryMaxBackoff},
+		{"AGENT_RETRY_DEADLINE", cfg.AgentRetryDeadline},
+		{"RESOLVED_MIN_INTERVAL", cfg.ResolvedMinInterval},
+		{"MIN_POD_AGE", cfg.MinPodAge},
+		{"DEPLOYMENT_UNAVAILABLE_THRESHOLD", cfg.DeploymentUnavailableThreshold},
+		{"DAEMONSET_DEGRADED_THRESHOLD", cfg.DaemonSetDegradedThreshold},
+	}
+	for _, v := range nonNegative {
+		if v.d < 0 {
+			return fmt.Errorf("%s must not be negative, got %v", v.name, v.d)
+		}
+	}
+	if _, err := parseAgentPath(cfg.AgentPath); err != nil {
+		return fmt.Errorf("invalid AGENT_PATH %q: %w", cfg.AgentPath, err)
+	}
+	if cfg.WatchFailureThreshold < 0 {
+		return fmt.Errorf("WATCH_FAILURE_THRESHOLD must not be negative, got %d", cfg.WatchFailureThreshold)
+	}
+	// Pods are re-buffered if their reservation expires while the node window is still open
+	if cfg.NodeCorrelation && cfg.AlertCooldown > 0 && cfg.NodeCorrelationWindow >= cfg.AlertCooldown {
+		return fmt.Errorf("NODE_CORRELATION_WINDOW must be shorter than ALERT_COOLDOWN (%v), got %v", cfg.AlertCooldown, cfg.NodeCorrelationWindow)
+	}
+	if cfg.CorrelationLabel != "" && cfg.AlertCooldown > 0 && cfg.CorrelationWindow >= cfg.AlertCooldown {
+		return fmt.Errorf("CORRELATION_WINDOW must be shorter than ALERT_COOLDOWN (%v), got %v", cfg.AlertCooldown, cfg.CorrelationWindow)
+	}
+
+	if cfg.AlertCooldown == 0 {
+		log.Printf("WARNING: ALERT_COOLDOWN is 0, so failing pods are re-alerted on every observation (at least every RECHECK_INTERVAL, %v)", cfg.RecheckInterval)
+	}
+	warnZeroCooldowns("NAMESPACE_COOLDOWNS", cfg.NamespaceCooldowns)
+	warnZeroCooldowns("REASON_COOLDOWNS", cfg.ReasonCooldowns)
+	if len(cfg.ReasonCooldowns) > 0 && len(cfg.NamespaceCooldowns) > 0 {
+		log.Printf("Cooldown precedence: watch-my-pod/cooldown annotation > PodAlertRule > REASON_COOLDOWNS > NAMESPACE_COOLDOWNS > ALERT_COOLDOWN")
+	}
+	if cfg.RecheckInterval < time.Second {
+		log.Printf("WARNING: RECHECK_INTERVAL is %v; re-evaluating every pod this often puts load on the monitor", cfg.RecheckInterval)
+	}
+	return nil
+}
+
+// warnZeroCooldowns logs a warning for every cooldown override of 0, in a stable order
+func warnZeroCooldowns(name string, cooldowns map[string]time.Duration) {
+	keys := make([]string, 0, len(cooldowns))
+	for k := range cooldowns {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if cooldowns[k] == 0 {
+			log.Printf("WARNING: %s sets a cooldown of 0 for %s, so matching pods are re-alerted on every observation", name, k)
+		}
+	}
+}
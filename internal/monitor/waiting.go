@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// stuckWaitingReason is reported for a container stuck waiting without a reason
+const stuckWaitingReason = "Waiting"
+
+// checkStuckWaiting catches containers that have been waiting longer than
+// WAITING_TIMEOUT for any reason the specific checks don't handle, e.g. a
+// PodInitializing that never ends. Waiting containers stop producing updates,
+// so this relies on the periodic recheck to notice the deadline passing.
+func (c *Controller) checkStuckWaiting(pod *corev1.Pod, ignored []string) (bool, badState) {
+	if c.cfg.WaitingTimeout <= 0 {
+		return false, badState{}
+	}
+	// A stuck init container is the cause of the main containers' PodInitializing, so report it first
+	for i, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, cs := range statuses {
+			w := cs.State.Waiting
+			if w == nil || matchesAny(ignored, cs.Name) {
+				continue
+			}
+			// The specific checks only look at the main containers
+			if i == 1 && c.handlesWaitingReason(w.Reason) {
+				continue
+			}
+			since := waitingSince(pod, cs)
+			if c.clock.Since(since) <= c.cfg.WaitingTimeout {
+				continue
+			}
+			reason := w.Reason
+			if reason == "" {
+				reason = stuckWaitingReason
+			}
+			return true, badState{
+				Reason:    reason,
+				Container: cs.Name,
+				Detail:    truncate(w.Message, badStateDetailLimit),
+				Since:     since,
+			}
+		}
+	}
+	return false, badState{}
+}
+
+// handlesWaitingReason reports whether a specific check already decides when the waiting reason is bad
+func (c *Controller) handlesWaitingReason(reason string) bool {
+	if reason == containerCreatingReason {
+		return c.cfg.ContainerCreatingTimeout > 0
+	}
+	return imagePullReasons[reason] || reason == "CrashLoopBackOff"
+}
+
+// waitingSince estimates when a container started waiting, since the waiting state carries no timestamp
+func waitingSince(pod *corev1.Pod, cs corev1.ContainerStatus) time.Time {
+	if last := cs.LastTerminationState.Terminated; last != nil && !last.FinishedAt.IsZero() {
+		return last.FinishedAt.Time
+	}
+	return podScheduledTime(pod)
+}
@@ -0,0 +1,92 @@
+package monitor
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+)
+
+// watchWatchdog counts an informer's watch failures in a sliding window.
+// Past the threshold the process exits, so Kubernetes restarts it with
+// fresh credentials and connections instead of it silently serving stale data.
+// An event from the API server shows the watch recovered and clears the count.
+type watchWatchdog struct {
+	clock     Clock
+	threshold int
+	window    time.Duration
+
+	mu       sync.Mutex
+	failures []time.Time
+}
+
+// handler returns a watch error handler for the named informer
+func (w *watchWatchdog) handler(name string) cache.WatchErrorHandler {
+	return func(r *cache.Reflector, err error) {
+		cache.DefaultWatchErrorHandler(r, err)
+
+		if n := w.record(); n >= w.threshold {
+			log.Fatalf("%s informer failed to watch %d times within %v, exiting to recover: %v", name, n, w.window, err)
+		}
+	}
+}
+
+// record adds a failure and returns the number of failures within the window
+func (w *watchWatchdog) record() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.clock.Now()
+	kept := w.failures[:0]
+	for _, t := range w.failures {
+		if now.Sub(t) < w.window {
+			kept = append(kept, t)
+		}
+	}
+	w.failures = append(kept, now)
+	return len(w.failures)
+}
+
+// reset forgets the failures once the watch delivers again
+func (w *watchWatchdog) reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.failures = w.failures[:0]
+}
+
+// deliveries returns an event handler that resets the watchdog on every event
+// the API server delivered; periodic resyncs replay the local cache and don't count
+func (w *watchWatchdog) deliveries() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(interface{}) { w.reset() },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldMeta, errOld := meta.Accessor(oldObj)
+			newMeta, errNew := meta.Accessor(newObj)
+			if errOld == nil && errNew == nil && oldMeta.GetResourceVersion() != newMeta.GetResourceVersion() {
+				w.reset()
+			}
+		},
+		DeleteFunc: func(interface{}) { w.reset() },
+	}
+}
+
+// installWatchdog attaches a watchdog to the informer; it must run before the informer starts
+func (c *Controller) installWatchdog(name string, informer cache.SharedIndexInformer) {
+	if c.cfg.WatchFailureThreshold <= 0 {
+		return
+	}
+	w := &watchWatchdog{
+		clock:     c.clock,
+		threshold: c.cfg.WatchFailureThreshold,
+		window:    c.cfg.WatchFailureWindow,
+	}
+	if err := informer.SetWatchErrorHandler(w.handler(name)); err != nil {
+		log.Printf("ERROR: Failed to install watch watchdog on %s informer: %v", name, err)
+		return
+	}
+	if _, err := informer.AddEventHandler(w.deliveries()); err != nil {
+		log.Printf("WARNING: Failed to track deliveries of the %s informer, its watch failures are never cleared: %v", name, err)
+	}
+}